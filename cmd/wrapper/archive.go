@@ -0,0 +1,238 @@
+//go:build !minimal
+
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Archive sources: a .zip payload (local path or downloaded) is
+// extracted next to the archive into <archive>.extracted, and that
+// directory becomes the sync source. Extraction is skipped when the
+// directory is already newer than the archive.
+
+// isArchive reports whether the path looks like a payload archive the
+// wrapper can extract.
+func isArchive(path string) bool {
+	return archiveKind(path) != ""
+}
+
+// archiveKind classifies a payload path by extension: "zip", "tar",
+// "tar.gz", "tar.xz", "tar.zst" or "" for plain files.
+func archiveKind(path string) string {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return "zip"
+	case strings.HasSuffix(lower, ".tar"):
+		return "tar"
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return "tar.gz"
+	case strings.HasSuffix(lower, ".tar.xz"), strings.HasSuffix(lower, ".txz"):
+		return "tar.xz"
+	case strings.HasSuffix(lower, ".tar.zst"), strings.HasSuffix(lower, ".tzst"):
+		return "tar.zst"
+	case strings.HasSuffix(lower, ".7z"):
+		return "7z"
+	}
+	return ""
+}
+
+// extractSource ensures an extracted directory exists for the given
+// archive and returns it.
+func extractSource(archive string, cfg *Config) (string, error) {
+	dir := archive + ".extracted"
+	ai, err := os.Stat(archive)
+	if err != nil {
+		return "", err
+	}
+	if di, err := os.Stat(dir); err == nil && di.ModTime().After(ai.ModTime()) {
+		return dir, nil
+	}
+	// Re-extract from scratch so stale entries from a previous
+	// archive version cannot linger.
+	if err := os.RemoveAll(dir); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	log.Printf("extracting %s", filepath.Base(archive))
+	var xerr error
+	switch kind := archiveKind(archive); kind {
+	case "zip":
+		xerr = extractZip(archive, dir)
+	case "7z":
+		xerr = extract7z(archive, dir, cfg)
+	default:
+		xerr = extractTar(archive, kind, dir)
+	}
+	if xerr != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("extract %s: %w", filepath.Base(archive), xerr)
+	}
+	// Bump the dir mtime above the archive's so the check above holds
+	// even on filesystems with coarse timestamps.
+	now := ai.ModTime().Add(1)
+	os.Chtimes(dir, now, now)
+	return dir, nil
+}
+
+// extractZip unpacks a zip archive into dir, preserving the
+// permission bits recorded by the archive. Zip entries are compressed
+// independently, so regular files are decompressed by a bounded pool
+// of workers; on fast SSDs single-threaded inflate is the bottleneck,
+// not the disk.
+func extractZip(archive, dir string) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	// Directories first (serially), so concurrent file writes never
+	// race on MkdirAll, then files in parallel, symlinks last.
+	var files, symlinks []*zip.File
+	for _, f := range zr.File {
+		rel, err := sanitizeEntryName(f.Name)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			continue
+		}
+		target := filepath.Join(dir, filepath.FromSlash(rel))
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			if err := os.MkdirAll(target, dirPerm(mode)); err != nil {
+				return err
+			}
+		case mode&os.ModeSymlink != 0:
+			symlinks = append(symlinks, f)
+		case mode.IsRegular():
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			files = append(files, f)
+		default:
+			return fmt.Errorf("entry %q: unsupported file type %v", f.Name, mode)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errChan := make(chan error, 1)
+	sem := make(chan struct{}, extractWorkers())
+	for _, f := range files {
+		f := f
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			rel, _ := sanitizeEntryName(f.Name)
+			target := filepath.Join(dir, filepath.FromSlash(rel))
+			if err := writeZipEntry(f, target); err != nil {
+				select {
+				case errChan <- fmt.Errorf("entry %q: %w", f.Name, err):
+				default:
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	select {
+	case err := <-errChan:
+		return err
+	default:
+	}
+
+	for _, f := range symlinks {
+		rel, _ := sanitizeEntryName(f.Name)
+		target := filepath.Join(dir, filepath.FromSlash(rel))
+		link, err := readZipEntry(f, 4096)
+		if err != nil {
+			return err
+		}
+		if err := checkLinkTarget(dir, target, string(link)); err != nil {
+			return fmt.Errorf("entry %q: %w", f.Name, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		os.Remove(target)
+		if err := os.Symlink(string(link), target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractWorkers bounds concurrent entry decompression.
+func extractWorkers() int {
+	n := runtime.NumCPU()
+	if n > 8 {
+		n = 8
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+func writeZipEntry(f *zip.File, target string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, filePerm(f.Mode()))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if !f.Modified.IsZero() {
+		os.Chtimes(target, f.Modified, f.Modified)
+	}
+	return nil
+}
+
+func readZipEntry(f *zip.File, limit int64) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(io.LimitReader(rc, limit))
+}
+
+// filePerm returns the permission bits to apply to an extracted file,
+// falling back to 0644 for archives (typically from Windows) that
+// record none.
+func filePerm(mode os.FileMode) os.FileMode {
+	if p := mode.Perm(); p != 0 {
+		return p
+	}
+	return 0o644
+}
+
+func dirPerm(mode os.FileMode) os.FileMode {
+	if p := mode.Perm(); p != 0 {
+		return p | 0o700 // owner must always be able to descend
+	}
+	return 0o755
+}
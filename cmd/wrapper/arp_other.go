@@ -0,0 +1,8 @@
+//go:build !windows || minimal
+
+package main
+
+// The Apps & Features entry is a Windows concept; ignored elsewhere.
+
+func writeAppsEntry(dest string, cfg *Config) error { return nil }
+func removeAppsEntry(cfg *Config) error             { return nil }
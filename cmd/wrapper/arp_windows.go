@@ -0,0 +1,92 @@
+//go:build windows && !minimal
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// The Apps & Features entry is the per-user uninstall key; writing it
+// under HKCU needs no elevation and is what MSI does for user-scoped
+// installs too.
+
+const uninstallKeyPath = `Software\Microsoft\Windows\CurrentVersion\Uninstall\`
+
+// writeAppsEntry creates or refreshes the Add/Remove Programs entry
+// after a successful sync.
+func writeAppsEntry(dest string, cfg *Config) error {
+	ae := &cfg.AppsEntry
+	if !ae.Enabled {
+		return nil
+	}
+	if cfg.Name == "" {
+		return fmt.Errorf("apps_entry needs -name")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	k, _, err := registry.CreateKey(registry.CURRENT_USER, uninstallKeyPath+cfg.Name, registry.ALL_ACCESS)
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+
+	display := ae.DisplayName
+	if display == "" {
+		display = cfg.Name
+	}
+	version := ae.DisplayVersion
+	if version == "" {
+		if v := readVersion(dest); len(v) >= 12 {
+			version = v[:12]
+		} else {
+			version = v
+		}
+	}
+	k.SetStringValue("DisplayName", display)
+	k.SetStringValue("DisplayVersion", version)
+	if ae.Publisher != "" {
+		k.SetStringValue("Publisher", ae.Publisher)
+	}
+	k.SetStringValue("InstallLocation", dest)
+	k.SetStringValue("DisplayIcon", filepath.Join(dest, filepath.FromSlash(cfg.Entry)))
+	k.SetStringValue("UninstallString", fmt.Sprintf(`"%s" -uninstall`, exe))
+	k.SetDWordValue("NoModify", 1)
+	k.SetDWordValue("NoRepair", 1)
+	if kb := treeSizeKB(dest); kb > 0 {
+		k.SetDWordValue("EstimatedSize", uint32(kb))
+	}
+	recordChange("regkey", uninstallKeyPath+cfg.Name)
+	log.Printf("wrote Apps & Features entry %q", display)
+	return nil
+}
+
+// removeAppsEntry deletes the uninstall key.
+func removeAppsEntry(cfg *Config) error {
+	if cfg.Name == "" {
+		return nil
+	}
+	err := registry.DeleteKey(registry.CURRENT_USER, uninstallKeyPath+cfg.Name)
+	if err != nil && err != registry.ErrNotExist {
+		return err
+	}
+	return nil
+}
+
+// treeSizeKB sums the size of dest in KiB for EstimatedSize.
+func treeSizeKB(dest string) int64 {
+	var total int64
+	filepath.Walk(dest, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total / 1024
+}
@@ -0,0 +1,92 @@
+//go:build linux && !minimal
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// On Linux, associations are a .desktop file in the user's
+// applications dir declaring the MimeTypes, made default with
+// xdg-mime. Associations without a mime_type are skipped, since
+// extensions alone mean nothing to the freedesktop stack.
+
+func desktopFilePath(cfg *Config) (string, error) {
+	if cfg.Name == "" {
+		return "", fmt.Errorf("file associations need -name")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "applications", cfg.Name+".desktop"), nil
+}
+
+func registerFileAssociations(dest string, cfg *Config) error {
+	if len(cfg.FileAssociations) == 0 && len(cfg.Protocols) == 0 {
+		return nil
+	}
+	var mimes []string
+	for _, fa := range cfg.FileAssociations {
+		if fa.MimeType == "" {
+			log.Printf("association %s has no mime_type; skipped on Linux", fa.Extension)
+			continue
+		}
+		mimes = append(mimes, fa.MimeType)
+	}
+	for _, scheme := range cfg.Protocols {
+		if err := validateScheme(scheme); err != nil {
+			return err
+		}
+		mimes = append(mimes, "x-scheme-handler/"+scheme)
+	}
+	if len(mimes) == 0 {
+		return nil
+	}
+	path, err := desktopFilePath(cfg)
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	content := fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=%s
+Exec=%s -- %%F
+MimeType=%s;
+NoDisplay=true
+`, cfg.Name, exe, strings.Join(mimes, ";"))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return err
+	}
+	for _, m := range mimes {
+		// Best effort: a headless box may not have xdg-utils.
+		if out, err := exec.Command("xdg-mime", "default", filepath.Base(path), m).CombinedOutput(); err != nil {
+			log.Printf("xdg-mime default %s: %v\n%s", m, err, out)
+		}
+	}
+	recordChange("file", path)
+	log.Printf("registered %d association(s) via %s", len(mimes), path)
+	return nil
+}
+
+func unregisterFileAssociations(cfg *Config) error {
+	path, err := desktopFilePath(cfg)
+	if err != nil {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build (!windows && !linux) || minimal
+
+package main
+
+import "log"
+
+func registerFileAssociations(dest string, cfg *Config) error {
+	if len(cfg.FileAssociations) > 0 {
+		log.Printf("file associations are not supported on this platform; ignoring")
+	}
+	return nil
+}
+
+func unregisterFileAssociations(cfg *Config) error { return nil }
@@ -0,0 +1,119 @@
+//go:build windows && !minimal
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// File associations live entirely under HKCU\Software\Classes so no
+// elevation is needed: one ProgID key per type with an open command
+// pointing at the wrapper, and the extension key's default value set
+// to the ProgID.
+
+func assocProgID(fa *FileAssoc, cfg *Config) string {
+	if fa.ProgID != "" {
+		return fa.ProgID
+	}
+	return cfg.Name + strings.TrimPrefix(fa.Extension, ".")
+}
+
+// registerFileAssociations writes the configured associations.
+func registerFileAssociations(dest string, cfg *Config) error {
+	if len(cfg.FileAssociations) == 0 {
+		return nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	for i := range cfg.FileAssociations {
+		fa := &cfg.FileAssociations[i]
+		if !strings.HasPrefix(fa.Extension, ".") {
+			return fmt.Errorf("file association extension %q must start with a dot", fa.Extension)
+		}
+		progID := assocProgID(fa, cfg)
+		if progID == "" || progID == strings.TrimPrefix(fa.Extension, ".") {
+			return fmt.Errorf("file association %s needs -name or an explicit progid", fa.Extension)
+		}
+		icon := filepath.Join(dest, filepath.FromSlash(cfg.Entry)) + ",0"
+		if fa.Icon != "" {
+			icon = filepath.Join(dest, filepath.FromSlash(fa.Icon))
+		}
+		if err := writeProgID(progID, fa.Description, icon, exe); err != nil {
+			return fmt.Errorf("register %s: %w", fa.Extension, err)
+		}
+		if err := setClassValue(fa.Extension, "", progID); err != nil {
+			return fmt.Errorf("register %s: %w", fa.Extension, err)
+		}
+		recordChange("regkey", `Software\Classes\`+progID)
+		recordChange("regvalue", `Software\Classes\`+fa.Extension+"|")
+		log.Printf("associated %s with %s", fa.Extension, progID)
+	}
+	return nil
+}
+
+// unregisterFileAssociations removes what registerFileAssociations
+// wrote, leaving the extension key alone if someone else took it over
+// in the meantime.
+func unregisterFileAssociations(cfg *Config) error {
+	for i := range cfg.FileAssociations {
+		fa := &cfg.FileAssociations[i]
+		progID := assocProgID(fa, cfg)
+		if cur, err := readClassValue(fa.Extension, ""); err == nil && cur == progID {
+			deleteClassKey(fa.Extension)
+		}
+		deleteClassKey(progID + `\shell\open\command`)
+		deleteClassKey(progID + `\shell\open`)
+		deleteClassKey(progID + `\shell`)
+		deleteClassKey(progID + `\DefaultIcon`)
+		deleteClassKey(progID)
+		log.Printf("removed association %s (%s)", fa.Extension, progID)
+	}
+	return nil
+}
+
+func writeProgID(progID, description, icon, exe string) error {
+	if err := setClassValue(progID, "", description); err != nil {
+		return err
+	}
+	if err := setClassValue(progID+`\DefaultIcon`, "", icon); err != nil {
+		return err
+	}
+	cmd := fmt.Sprintf(`"%s" "%%1"`, exe)
+	return setClassValue(progID+`\shell\open\command`, "", cmd)
+}
+
+func classesKey(path string) (registry.Key, error) {
+	k, _, err := registry.CreateKey(registry.CURRENT_USER, `Software\Classes\`+path, registry.ALL_ACCESS)
+	return k, err
+}
+
+func setClassValue(path, name, value string) error {
+	k, err := classesKey(path)
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+	return k.SetStringValue(name, value)
+}
+
+func readClassValue(path, name string) (string, error) {
+	k, err := registry.OpenKey(registry.CURRENT_USER, `Software\Classes\`+path, registry.QUERY_VALUE)
+	if err != nil {
+		return "", err
+	}
+	defer k.Close()
+	v, _, err := k.GetStringValue(name)
+	return v, err
+}
+
+func deleteClassKey(path string) {
+	registry.DeleteKey(registry.CURRENT_USER, `Software\Classes\`+path)
+}
@@ -0,0 +1,69 @@
+//go:build darwin && !minimal
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// launchAgentPath returns the per-user LaunchAgent plist location.
+// The label mirrors the reverse-DNS convention loosely by prefixing
+// the install name.
+func launchAgentPath(cfg *Config) (string, error) {
+	if cfg.Name == "" {
+		return "", fmt.Errorf("autostart needs -name")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", "wrapper."+cfg.Name+".plist"), nil
+}
+
+func registerAutostart(cfg *Config) error {
+	if !cfg.Autostart {
+		return nil
+	}
+	path, err := launchAgentPath(cfg)
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	plist := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key><string>wrapper.%s</string>
+	<key>ProgramArguments</key>
+	<array><string>%s</string></array>
+	<key>RunAtLoad</key><true/>
+</dict>
+</plist>
+`, cfg.Name, exe)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(plist), 0o644); err != nil {
+		return err
+	}
+	recordChange("file", path)
+	log.Printf("registered LaunchAgent %s", path)
+	return nil
+}
+
+func unregisterAutostart(cfg *Config) error {
+	path, err := launchAgentPath(cfg)
+	if err != nil {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
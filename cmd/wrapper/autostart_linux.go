@@ -0,0 +1,66 @@
+//go:build linux && !minimal
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// autostartPath returns the freedesktop autostart entry location.
+func autostartPath(cfg *Config) (string, error) {
+	if cfg.Name == "" {
+		return "", fmt.Errorf("autostart needs -name")
+	}
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "autostart", cfg.Name+".desktop"), nil
+}
+
+func registerAutostart(cfg *Config) error {
+	if !cfg.Autostart {
+		return nil
+	}
+	path, err := autostartPath(cfg)
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	content := fmt.Sprintf(`[Desktop Entry]
+Type=Application
+Name=%s
+Exec=%s
+X-GNOME-Autostart-enabled=true
+`, cfg.Name, exe)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return err
+	}
+	recordChange("file", path)
+	log.Printf("registered autostart entry %s", path)
+	return nil
+}
+
+func unregisterAutostart(cfg *Config) error {
+	path, err := autostartPath(cfg)
+	if err != nil {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
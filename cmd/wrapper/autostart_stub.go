@@ -0,0 +1,14 @@
+//go:build (!windows && !linux && !darwin) || minimal
+
+package main
+
+import "log"
+
+func registerAutostart(cfg *Config) error {
+	if cfg.Autostart {
+		log.Printf("autostart is not supported on this platform; ignoring")
+	}
+	return nil
+}
+
+func unregisterAutostart(cfg *Config) error { return nil }
@@ -0,0 +1,58 @@
+//go:build windows && !minimal
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const runKeyPath = `Software\Microsoft\Windows\CurrentVersion\Run`
+
+// registerAutostart adds the wrapper to the per-user Run key so
+// sync-and-launch happens at login.
+func registerAutostart(cfg *Config) error {
+	if !cfg.Autostart {
+		return nil
+	}
+	if cfg.Name == "" {
+		return fmt.Errorf("autostart needs -name")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	k, _, err := registry.CreateKey(registry.CURRENT_USER, runKeyPath, registry.ALL_ACCESS)
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+	if err := k.SetStringValue(cfg.Name, fmt.Sprintf(`"%s"`, exe)); err != nil {
+		return err
+	}
+	recordChange("regvalue", runKeyPath+"|"+cfg.Name)
+	log.Printf("registered autostart (Run key %q)", cfg.Name)
+	return nil
+}
+
+// unregisterAutostart removes the Run key value.
+func unregisterAutostart(cfg *Config) error {
+	if cfg.Name == "" {
+		return nil
+	}
+	k, err := registry.OpenKey(registry.CURRENT_USER, runKeyPath, registry.ALL_ACCESS)
+	if err != nil {
+		if err == registry.ErrNotExist {
+			return nil
+		}
+		return err
+	}
+	defer k.Close()
+	if err := k.DeleteValue(cfg.Name); err != nil && err != registry.ErrNotExist {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+//go:build darwin && !minimal
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// The generated bundle is the bare minimum Launch Services needs:
+// Contents/Info.plist plus Contents/MacOS/<Name> symlinked to the
+// wrapper binary. Launching the bundle therefore syncs first, like
+// every other entry point.
+
+func bundlePath(cfg *Config) (string, error) {
+	if cfg.Name == "" {
+		return "", fmt.Errorf("mac_app_bundle needs -name")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Applications", cfg.Name+".app"), nil
+}
+
+// createAppBundle writes or refreshes the .app wrapper bundle.
+func createAppBundle(cfg *Config) error {
+	if !cfg.MacAppBundle {
+		return nil
+	}
+	bundle, err := bundlePath(cfg)
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	macOSDir := filepath.Join(bundle, "Contents", "MacOS")
+	if err := os.MkdirAll(macOSDir, 0o755); err != nil {
+		return err
+	}
+	link := filepath.Join(macOSDir, cfg.Name)
+	os.Remove(link)
+	if err := os.Symlink(exe, link); err != nil {
+		return err
+	}
+	plist := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleName</key><string>%s</string>
+	<key>CFBundleExecutable</key><string>%s</string>
+	<key>CFBundleIdentifier</key><string>wrapper.%s</string>
+	<key>CFBundlePackageType</key><string>APPL</string>
+	<key>CFBundleInfoDictionaryVersion</key><string>6.0</string>
+	<key>LSMinimumSystemVersion</key><string>10.13</string>
+</dict>
+</plist>
+`, cfg.Name, cfg.Name, cfg.Name)
+	if err := os.WriteFile(filepath.Join(bundle, "Contents", "Info.plist"), []byte(plist), 0o644); err != nil {
+		return err
+	}
+	recordChange("dir", bundle)
+	log.Printf("created app bundle %s", bundle)
+	return nil
+}
+
+// removeAppBundle deletes the generated bundle at uninstall.
+func removeAppBundle(cfg *Config) error {
+	bundle, err := bundlePath(cfg)
+	if err != nil {
+		return nil
+	}
+	if err := os.RemoveAll(bundle); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build !darwin || minimal
+
+package main
+
+// App bundles only exist on macOS; the option is inert elsewhere.
+
+func createAppBundle(cfg *Config) error { return nil }
+func removeAppBundle(cfg *Config) error { return nil }
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// The change manifest records every side effect the wrapper creates
+// outside plain payload files — shortcuts, registry keys, PATH edits,
+// firewall rules — so uninstall can replay them in reverse and leave
+// nothing behind, even if the config changed since they were made.
+//
+// Entries are generic (kind + item) rather than per-feature, so one
+// small dispatcher can undo everything:
+//
+//	file          delete the file at item
+//	dir           delete the tree at item
+//	regkey        delete the HKCU key tree at item      (Windows)
+//	regvalue      delete "keypath|valuename"            (Windows)
+//	path_entry    remove item from the user PATH        (Windows)
+//	profile_block remove the ~/.profile block for item  (Unix)
+//	firewall_rule delete the netsh rule named item      (Windows)
+
+const changesFileName = ".wrapper-changes.json"
+
+type change struct {
+	Kind string    `json:"kind"`
+	Item string    `json:"item"`
+	At   time.Time `json:"at"`
+}
+
+type changeManifest struct {
+	Changes []change `json:"changes"`
+}
+
+// changes is the manifest for the current run; integrations append to
+// it via recordChange and main persists it after the install phase.
+var changes = &changeManifest{}
+
+// recordChange notes a side effect. Duplicate kind+item pairs are
+// collapsed so repeated syncs do not grow the manifest.
+func recordChange(kind, item string) {
+	for _, c := range changes.Changes {
+		if c.Kind == kind && c.Item == item {
+			return
+		}
+	}
+	changes.Changes = append(changes.Changes, change{Kind: kind, Item: item, At: time.Now()})
+}
+
+// loadChanges reads the manifest recorded by previous runs, so this
+// run extends it rather than starting over.
+func loadChanges(dest string) {
+	data, err := os.ReadFile(filepath.Join(dest, changesFileName))
+	if err != nil {
+		return
+	}
+	var m changeManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		log.Printf("change manifest unreadable, starting fresh: %v", err)
+		return
+	}
+	changes = &m
+}
+
+// saveChanges persists the manifest into dest.
+func saveChanges(dest string) error {
+	if len(changes.Changes) == 0 {
+		return nil
+	}
+	data, err := json.MarshalIndent(changes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dest, changesFileName), append(data, '\n'), 0o644)
+}
+
+// undoChanges replays the manifest in reverse. It reports whether a
+// manifest existed; when it did not, the caller falls back to
+// config-driven removal.
+func undoChanges(dest string, cfg *Config) bool {
+	loadChanges(dest)
+	if len(changes.Changes) == 0 {
+		return false
+	}
+	for i := len(changes.Changes) - 1; i >= 0; i-- {
+		c := changes.Changes[i]
+		if err := undoChange(c, dest, cfg); err != nil {
+			log.Printf("undo %s %q: %v", c.Kind, c.Item, err)
+		}
+	}
+	return true
+}
+
+// undoChange reverts a single recorded side effect.
+func undoChange(c change, dest string, cfg *Config) error {
+	switch c.Kind {
+	case "file":
+		if err := os.Remove(c.Item); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	case "dir":
+		return os.RemoveAll(c.Item)
+	default:
+		if ok, err := undoPlatformChange(c, dest, cfg); ok {
+			return err
+		}
+		return fmt.Errorf("unknown change kind %q", c.Kind)
+	}
+}
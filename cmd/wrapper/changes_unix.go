@@ -0,0 +1,39 @@
+//go:build !windows && !minimal
+
+package main
+
+import (
+	"os"
+)
+
+// undoPlatformChange handles the Unix-only change kinds.
+func undoPlatformChange(c change, dest string, cfg *Config) (bool, error) {
+	switch c.Kind {
+	case "profile_block":
+		profile, err := profilePath()
+		if err != nil {
+			return true, err
+		}
+		data, err := os.ReadFile(profile)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return true, nil
+			}
+			return true, err
+		}
+		start := "# >>> wrapper path (" + c.Item + ") >>>"
+		end := "# <<< wrapper path (" + c.Item + ") <<<"
+		stripped := stripPathBlock(string(data), start, end)
+		if stripped == string(data) {
+			return true, nil
+		}
+		return true, os.WriteFile(profile, []byte(stripped), 0o644)
+	}
+	// Kinds recorded on Windows can show up if a dest is carried
+	// across machines; treat them as handled no-ops.
+	switch c.Kind {
+	case "regkey", "regvalue", "path_entry", "firewall_rule":
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,94 @@
+//go:build windows && !minimal
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// undoPlatformChange handles the Windows-only change kinds.
+func undoPlatformChange(c change, dest string, cfg *Config) (bool, error) {
+	switch c.Kind {
+	case "regkey":
+		return true, regDeleteTree(c.Item)
+	case "regvalue":
+		keyPath, valueName, ok := strings.Cut(c.Item, "|")
+		if !ok {
+			return true, fmt.Errorf("malformed regvalue item")
+		}
+		k, err := registry.OpenKey(registry.CURRENT_USER, keyPath, registry.ALL_ACCESS)
+		if err != nil {
+			if err == registry.ErrNotExist {
+				return true, nil
+			}
+			return true, err
+		}
+		defer k.Close()
+		if err := k.DeleteValue(valueName); err != nil && err != registry.ErrNotExist {
+			return true, err
+		}
+		return true, nil
+	case "path_entry":
+		return true, removeUserPathEntry(c.Item)
+	case "firewall_rule":
+		out, err := exec.Command("netsh", "advfirewall", "firewall", "delete", "rule", "name="+c.Item).CombinedOutput()
+		if err != nil {
+			return true, fmt.Errorf("%v: %s", err, out)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// regDeleteTree removes an HKCU key and everything under it.
+func regDeleteTree(path string) error {
+	k, err := registry.OpenKey(registry.CURRENT_USER, path, registry.ALL_ACCESS)
+	if err != nil {
+		if err == registry.ErrNotExist {
+			return nil
+		}
+		return err
+	}
+	subs, err := k.ReadSubKeyNames(-1)
+	k.Close()
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if err := regDeleteTree(path + `\` + sub); err != nil {
+			return err
+		}
+	}
+	if err := registry.DeleteKey(registry.CURRENT_USER, path); err != nil && err != registry.ErrNotExist {
+		return err
+	}
+	return nil
+}
+
+// removeUserPathEntry strips one directory from the user PATH.
+func removeUserPathEntry(dir string) error {
+	k, val, typ, err := readUserPath()
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+	var kept []string
+	removed := false
+	for _, p := range strings.Split(val, ";") {
+		if strings.EqualFold(strings.TrimSpace(p), dir) {
+			removed = true
+			continue
+		}
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	if !removed {
+		return nil
+	}
+	return writeUserPath(k, strings.Join(kept, ";"), typ)
+}
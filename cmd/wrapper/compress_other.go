@@ -0,0 +1,9 @@
+//go:build !windows
+
+package main
+
+import "errors"
+
+// compressDest is Windows-only: NTFS compression has no counterpart
+// the wrapper could drive here.
+func compressDest(string) error { return errors.ErrUnsupported }
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+)
+
+// The configuration schema lives in pkg/config so embedders share it;
+// these aliases keep the rest of the package reading naturally.
+type (
+	Config         = config.Config
+	ShortcutConfig = config.ShortcutConfig
+	FileAssoc      = config.FileAssoc
+	Migration      = config.Migration
+	SetupAction    = config.SetupAction
+	Webhook        = config.Webhook
+	Plugin         = config.Plugin
+	FirewallRule   = config.FirewallRule
+	RegistryKey    = config.RegistryKey
+)
+
+// loadConfig prefers wrapper.config.json next to the executable and
+// falls back to an embedded branding default when none exists.
+func loadConfig() (*Config, error) {
+	cfg, found, err := config.LoadFile()
+	if err != nil || found {
+		return cfg, err
+	}
+	if data, ok := brandedFile(config.FileName); ok {
+		if cfg, err := config.Parse(data); err == nil {
+			return cfg, nil
+		} else {
+			return nil, fmt.Errorf("embedded %s: %w", config.FileName, err)
+		}
+	}
+	return cfg, nil
+}
+
+func machineInstallRoot() string { return config.MachineInstallRoot() }
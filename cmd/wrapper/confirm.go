@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Destructive operations — uninstall, a first mirror sync into a
+// directory with existing content — say what they are about to remove
+// and wait for a yes, instead of silently deleting on the user's
+// behalf. -y skips the prompts for scripts; runs without a terminal
+// proceed as before, since a service cannot answer.
+
+// assumeYes is set by -y.
+var assumeYes bool
+
+// previewLimit caps how many doomed entries a prompt lists.
+const previewLimit = 8
+
+// confirmRemoval shows the action and the items it will remove and
+// reads the answer; only an explicit "y" proceeds.
+func confirmRemoval(action string, items []string) bool {
+	if assumeYes || !stdinInteractive() {
+		return true
+	}
+	fmt.Fprintln(os.Stderr, action)
+	for _, it := range items {
+		fmt.Fprintf(os.Stderr, "  %s\n", it)
+	}
+	fmt.Fprint(os.Stderr, tr("Proceed? [y/N] "))
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// destPreview lists what already lives in dest, for the mirror-mode
+// prompt; wrapper metadata is not worth warning about.
+func destPreview(dest string) []string {
+	entries, err := os.ReadDir(dest)
+	if err != nil {
+		return nil
+	}
+	var items []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), wrapperMetaPrefix) {
+			continue
+		}
+		if len(items) == previewLimit {
+			items = append(items, "…")
+			break
+		}
+		name := e.Name()
+		if e.IsDir() {
+			name += string(os.PathSeparator)
+		}
+		items = append(items, name)
+	}
+	return items
+}
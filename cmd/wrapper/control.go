@@ -0,0 +1,178 @@
+//go:build !minimal
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/errcode"
+)
+
+// The control API lets other processes poke a resident wrapper: a unix
+// socket on unix-likes and a loopback TCP port (recorded next to the
+// socket file would live) on Windows. The protocol is one request line
+// per connection — status, sync, restart or shutdown — answered with
+// text and a connection close.
+
+// daemonState is what "status" reports, updated by the daemon loop and
+// read from control connections.
+type daemonState struct {
+	mu          sync.Mutex
+	version     string
+	childPID    int
+	lastSync    time.Time
+	lastResult  string
+	lastErrCode errcode.Code
+}
+
+func (st *daemonState) set(version string, pid int, result string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if version != "" {
+		st.version = version
+	}
+	st.childPID = pid
+	st.lastSync = time.Now()
+	st.lastResult = result
+	st.lastErrCode = ""
+}
+
+// setError records a failed sync along with its classified code, so
+// the status JSON and ctl output stay machine-branchable.
+func (st *daemonState) setError(version string, pid int, err error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if version != "" {
+		st.version = version
+	}
+	st.childPID = pid
+	st.lastSync = time.Now()
+	st.lastResult = "error: " + err.Error()
+	st.lastErrCode = errcode.Of(err)
+}
+
+// summary is the short status line the tray tooltip shows.
+func (st *daemonState) summary() string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.lastResult == "" {
+		return "starting"
+	}
+	return st.lastResult
+}
+
+func (st *daemonState) report() string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return fmt.Sprintf("version=%s child_pid=%d last_sync=%s last_result=%s",
+		st.version, st.childPID, st.lastSync.Format(time.RFC3339), st.lastResult)
+}
+
+// controlRequests carries control commands into the daemon loop.
+type controlRequests struct {
+	syncNow      chan struct{}
+	restart      chan struct{}
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+	state        *daemonState
+}
+
+// requestSync queues one sync unless one is already pending.
+func (r *controlRequests) requestSync() {
+	select {
+	case r.syncNow <- struct{}{}:
+	default:
+	}
+}
+
+// requestRestart queues one child restart unless one is pending.
+func (r *controlRequests) requestRestart() {
+	select {
+	case r.restart <- struct{}{}:
+	default:
+	}
+}
+
+// requestShutdown closes the shutdown channel exactly once, however
+// many control connections or tray clicks race to it.
+func (r *controlRequests) requestShutdown() {
+	r.shutdownOnce.Do(func() { close(r.shutdown) })
+}
+
+func newControlRequests(state *daemonState) *controlRequests {
+	return &controlRequests{
+		syncNow:  make(chan struct{}, 1),
+		restart:  make(chan struct{}, 1),
+		shutdown: make(chan struct{}),
+		state:    state,
+	}
+}
+
+// serveControl accepts connections until the listener closes.
+func serveControl(l net.Listener, reqs *controlRequests) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go handleControlConn(conn, reqs)
+	}
+}
+
+func handleControlConn(conn net.Conn, reqs *controlRequests) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return
+	}
+	switch cmd := strings.TrimSpace(line); cmd {
+	case "status":
+		fmt.Fprintln(conn, reqs.state.report())
+	case "sync":
+		reqs.requestSync()
+		fmt.Fprintln(conn, "ok")
+	case "restart":
+		reqs.requestRestart()
+		fmt.Fprintln(conn, "ok")
+	case "shutdown":
+		fmt.Fprintln(conn, "ok")
+		reqs.requestShutdown()
+	default:
+		fmt.Fprintf(conn, "unknown command %q\n", cmd)
+	}
+}
+
+// handleCtl implements "wrapper ctl <status|sync|restart|shutdown>",
+// talking to the resident daemon for the resolved dest.
+func handleCtl(cfg *Config, args []string) {
+	fs := flag.NewFlagSet("ctl", flag.ExitOnError)
+	fs.BoolVar(&jsonOut, "json", jsonOut, jsonFlagHelp)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fatalUsage("usage: wrapper ctl status|sync|restart|shutdown")
+	}
+	dest, err := cfg.ResolveDest()
+	if err != nil {
+		fatal("resolve destination", errcode.Wrap(errcode.ConfigInvalid, err))
+	}
+	conn, err := controlDial(dest)
+	if err != nil {
+		fatal("connect to daemon (is the wrapper running with -daemon?)", err)
+	}
+	defer conn.Close()
+	fmt.Fprintln(conn, fs.Arg(0))
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		fatal("read reply", err)
+	}
+	os.Stdout.Write(reply)
+}
@@ -0,0 +1,25 @@
+//go:build !windows && !minimal
+
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// controlSocketName is the unix socket the daemon listens on, kept
+// inside dest so each install has its own control surface.
+const controlSocketName = ".wrapper-control.sock"
+
+// controlListen binds the control socket, replacing a stale one from a
+// previous run.
+func controlListen(dest string) (net.Listener, error) {
+	path := filepath.Join(dest, controlSocketName)
+	os.Remove(path)
+	return net.Listen("unix", path)
+}
+
+func controlDial(dest string) (net.Conn, error) {
+	return net.Dial("unix", filepath.Join(dest, controlSocketName))
+}
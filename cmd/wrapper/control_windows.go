@@ -0,0 +1,41 @@
+//go:build windows && !minimal
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Windows has no unix sockets on all supported versions and a real
+// named pipe server needs overlapped I/O; a loopback TCP listener with
+// the port recorded in dest gives the same per-install control surface
+// with plain net code.
+
+// controlPortName holds the daemon's loopback port number.
+const controlPortName = ".wrapper-control"
+
+func controlListen(dest string) (net.Listener, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	path := filepath.Join(dest, controlPortName)
+	if err := os.WriteFile(path, []byte(fmt.Sprintf("%d\n", port)), 0o600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+func controlDial(dest string) (net.Conn, error) {
+	data, err := os.ReadFile(filepath.Join(dest, controlPortName))
+	if err != nil {
+		return nil, err
+	}
+	return net.Dial("tcp", "127.0.0.1:"+strings.TrimSpace(string(data)))
+}
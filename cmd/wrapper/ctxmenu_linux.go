@@ -0,0 +1,63 @@
+//go:build linux && !minimal
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// On Linux the entry is a Nautilus script: an executable file whose
+// name is the menu label, receiving the selection as arguments.
+
+func nautilusScriptPath(cfg *Config) (string, error) {
+	if cfg.Name == "" {
+		return "", fmt.Errorf("context_menu needs -name")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	label := cfg.ContextMenu.Label
+	if label == "" {
+		label = "Open with " + cfg.Name
+	}
+	return filepath.Join(home, ".local", "share", "nautilus", "scripts", label), nil
+}
+
+func registerContextMenu(dest string, cfg *Config) error {
+	if !cfg.ContextMenu.Enabled {
+		return nil
+	}
+	path, err := nautilusScriptPath(cfg)
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	script := fmt.Sprintf("#!/bin/sh\nexec %q -- \"$@\"\n", exe)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		return err
+	}
+	recordChange("file", path)
+	log.Printf("installed Nautilus script %s", path)
+	return nil
+}
+
+func unregisterContextMenu(cfg *Config) error {
+	path, err := nautilusScriptPath(cfg)
+	if err != nil {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
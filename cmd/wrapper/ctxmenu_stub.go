@@ -0,0 +1,14 @@
+//go:build (!windows && !linux) || minimal
+
+package main
+
+import "log"
+
+func registerContextMenu(dest string, cfg *Config) error {
+	if cfg.ContextMenu.Enabled {
+		log.Printf("context-menu integration is not supported on this platform; ignoring")
+	}
+	return nil
+}
+
+func unregisterContextMenu(cfg *Config) error { return nil }
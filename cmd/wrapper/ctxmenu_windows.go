@@ -0,0 +1,59 @@
+//go:build windows && !minimal
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// The context-menu verb lives under HKCU\Software\Classes\*\shell so
+// it applies to every file type without elevation. The verb key is
+// the install name; the label users see is its default value.
+
+func ctxMenuLabel(cfg *Config) string {
+	if cfg.ContextMenu.Label != "" {
+		return cfg.ContextMenu.Label
+	}
+	return "Open with " + cfg.Name
+}
+
+func registerContextMenu(dest string, cfg *Config) error {
+	if !cfg.ContextMenu.Enabled {
+		return nil
+	}
+	if cfg.Name == "" {
+		return fmt.Errorf("context_menu needs -name")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	verb := `*\shell\` + cfg.Name
+	if err := setClassValue(verb, "", ctxMenuLabel(cfg)); err != nil {
+		return err
+	}
+	if cfg.Entry != "" {
+		icon := filepath.Join(dest, filepath.FromSlash(cfg.Entry))
+		if err := setClassValue(verb, "Icon", icon); err != nil {
+			return err
+		}
+	}
+	if err := setClassValue(verb+`\command`, "", fmt.Sprintf(`"%s" "%%1"`, exe)); err != nil {
+		return err
+	}
+	recordChange("regkey", `Software\Classes\*\shell\`+cfg.Name)
+	log.Printf("registered context-menu verb %q", ctxMenuLabel(cfg))
+	return nil
+}
+
+func unregisterContextMenu(cfg *Config) error {
+	if cfg.Name == "" {
+		return nil
+	}
+	deleteClassKey(`*\shell\` + cfg.Name + `\command`)
+	deleteClassKey(`*\shell\` + cfg.Name)
+	return nil
+}
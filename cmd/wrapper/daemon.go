@@ -0,0 +1,137 @@
+//go:build !minimal
+
+package main
+
+import (
+	"context"
+	"log"
+	"os/exec"
+	"time"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/errcode"
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/platform"
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/launcher"
+)
+
+// Daemon mode keeps the wrapper resident and re-runs the sync pipeline
+// on a fixed interval, for installs driven by a service manager — a
+// systemd unit, a Windows service wrapper or a launchd job — instead
+// of by the user starting the app. The entry program is started once
+// up front; with restart_on_update set it is also killed and
+// relaunched after any periodic sync that changed dest. While
+// resident, a control socket accepts status/sync/restart/shutdown
+// requests from "wrapper ctl".
+func daemonLoop(ctx context.Context, dest string, cfg *Config, interval time.Duration, opts syncOptions, extraArgs []string, launch bool, statusAddr string) error {
+	state := &daemonState{}
+	reqs := newControlRequests(state)
+	if l, err := controlListen(dest); err != nil {
+		log.Printf("control socket: %v", err)
+	} else {
+		defer l.Close()
+		go serveControl(l, reqs)
+	}
+	if statusAddr != "" {
+		if l, err := startStatusServer(statusAddr, state); err != nil {
+			log.Printf("status endpoint: %v", err)
+		} else {
+			defer l.Close()
+		}
+	}
+
+	var child *exec.Cmd
+	childPID := func() int {
+		if child != nil && child.Process != nil {
+			return child.Process.Pid
+		}
+		return 0
+	}
+	startChild := func() {
+		if !launch || cfg.Entry == "" {
+			return
+		}
+		if err := runPlugins("pre_launch", dest, cfg, ""); err != nil {
+			log.Printf("pre-launch plugin: %v", err)
+			return
+		}
+		cmd, err := startDetached(dest, cfg, extraArgs)
+		if err != nil {
+			log.Printf("launch: %v", err)
+			return
+		}
+		child = cmd
+		// Reap the child when it exits so it cannot linger as a
+		// zombie for the lifetime of the daemon, and report crashes.
+		go func() {
+			if err := cmd.Wait(); err != nil {
+				log.Printf("entry exited: %v", err)
+				if path, werr := launcher.RecordCrash(dest, cfg.Entry, err, nil); werr != nil {
+					log.Printf("crash record: %v", werr)
+				} else if path != "" {
+					log.Printf("crash record written to %s", path)
+				}
+				fireWebhooks(cfg, "child_crash", readVersion(dest), err.Error())
+			}
+		}()
+	}
+	restartChild := func() {
+		if child != nil && child.Process != nil {
+			// Terminate the whole containment unit so helper
+			// processes the entry spawned do not survive the restart.
+			platform.Processes.Terminate(child.Process)
+		}
+		startChild()
+		metrics.recordChildRestart()
+	}
+	startChild()
+	state.set(readVersion(dest), childPID(), "startup")
+	if cfg.Tray {
+		go startTray(ctx, dest, cfg, reqs)
+	}
+
+	bgOpts := opts
+	bgOpts.background = true
+	doSync := func() {
+		state.set(readVersion(dest), childPID(), "syncing")
+		changed, err := syncOnce(ctx, dest, cfg, bgOpts)
+		if err != nil {
+			log.Printf("periodic sync [%s]: %v", errcode.Of(err), err)
+			notify(cfg, notifyTitle(cfg), "Update failed: "+err.Error())
+			fireWebhooks(cfg, "sync_failure", readVersion(dest), err.Error())
+			if perr := runPlugins("on_error", dest, cfg, err.Error()); perr != nil {
+				log.Printf("on-error plugin: %v", perr)
+			}
+			state.setError(readVersion(dest), childPID(), err)
+			return
+		}
+		if changed && cfg.RestartOnUpdate && child != nil {
+			log.Printf("restarting %s after update", cfg.Entry)
+			restartChild()
+		}
+		result := "up-to-date"
+		if changed {
+			result = "updated"
+		}
+		state.set(readVersion(dest), childPID(), result)
+	}
+
+	log.Printf("daemon mode: resyncing every %s", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("interrupted; shutting down")
+			return nil
+		case <-ticker.C:
+			doSync()
+		case <-reqs.syncNow:
+			doSync()
+		case <-reqs.restart:
+			restartChild()
+			state.set("", childPID(), "restarted")
+		case <-reqs.shutdown:
+			log.Printf("shutdown requested")
+			return nil
+		}
+	}
+}
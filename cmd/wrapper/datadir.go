@@ -0,0 +1,81 @@
+package main
+
+import (
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/launcher"
+
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// The data dir keeps app-generated state out of the synced program
+// tree: the entry program finds it in WRAPPER_DATA_DIR and mirror
+// syncs can stay aggressive about dest. A data dir outside dest also
+// survives uninstall, which is the point — wiping user data needs a
+// deliberate decision, not a side effect.
+
+// setupDataDir creates the managed data directory, exports it to the
+// entry's environment and, when it lives under dest, shields it from
+// mirror deletion and uninstall via the protected list. It returns
+// the resolved path, or "" when the feature is off.
+func setupDataDir(dest string, cfg *Config) (string, error) {
+	dir, err := resolveDataDir(dest, cfg)
+	if err != nil || dir == "" {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	launcher.AppendEnv("WRAPPER_DATA_DIR=" + dir)
+	if rel, err := filepath.Rel(dest, dir); err == nil && !filepath.IsAbs(rel) &&
+		rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		cfg.Protected = append(cfg.Protected, filepath.ToSlash(rel))
+	}
+	log.Printf("data dir %s", dir)
+	return dir, nil
+}
+
+// resolveDataDir maps the data_dir setting to a path: "" disables the
+// feature, "auto" picks the platform's per-user data home for Name,
+// anything else is used as given (dest-relative when not absolute).
+func resolveDataDir(dest string, cfg *Config) (string, error) {
+	switch cfg.DataDir {
+	case "":
+		return "", nil
+	case "auto":
+		if cfg.Name == "" {
+			return "", fmt.Errorf(`data_dir "auto" needs -name`)
+		}
+		base, err := userDataRoot()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(base, cfg.Name), nil
+	default:
+		if filepath.IsAbs(cfg.DataDir) {
+			return cfg.DataDir, nil
+		}
+		return filepath.Join(dest, filepath.FromSlash(cfg.DataDir)), nil
+	}
+}
+
+// userDataRoot is %APPDATA% on Windows, ~/Library/Application Support
+// on macOS and XDG data home on everything else.
+func userDataRoot() (string, error) {
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		return os.UserConfigDir()
+	default:
+		if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+			return xdg, nil
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, ".local", "share"), nil
+	}
+}
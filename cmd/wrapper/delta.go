@@ -0,0 +1,212 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/bzip2"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A delta bundle updates an existing dest from one payload version to
+// the next without shipping full file contents. It is a tar file
+// containing delta.json followed by a patches/<path> entry (BSDIFF40)
+// for every changed file:
+//
+//	{
+//	  "from_version": "<digest of the old payload>",
+//	  "to_version":   "<digest of the new payload>",
+//	  "files": [
+//	    {"path": "Aiwb.exe", "old_sha256": "…", "new_sha256": "…"},
+//	    ...
+//	  ]
+//	}
+//
+// Publishers generate patches with stock bsdiff; only application
+// lives in the wrapper.
+
+type deltaManifest struct {
+	FromVersion string      `json:"from_version"`
+	ToVersion   string      `json:"to_version"`
+	Files       []deltaFile `json:"files"`
+}
+
+type deltaFile struct {
+	Path      string `json:"path"`
+	OldSHA256 string `json:"old_sha256"`
+	NewSHA256 string `json:"new_sha256"`
+}
+
+// applyDeltaBundle patches dest in place from a delta bundle. The
+// bundle's from_version must match the version recorded in dest, and
+// every file is hash-checked before and after patching. Patched
+// files are written to a temp name and renamed, so a failed patch
+// leaves the original in place.
+func applyDeltaBundle(bundle, dest string) error {
+	f, err := os.Open(bundle)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	tr := tar.NewReader(f)
+
+	hdr, err := tr.Next()
+	if err != nil {
+		return fmt.Errorf("read delta bundle: %w", err)
+	}
+	if filepath.Base(hdr.Name) != "delta.json" {
+		return fmt.Errorf("delta bundle must start with delta.json, got %s", hdr.Name)
+	}
+	var man deltaManifest
+	if err := json.NewDecoder(io.LimitReader(tr, 16<<20)).Decode(&man); err != nil {
+		return fmt.Errorf("parse delta.json: %w", err)
+	}
+	if cur := readVersion(dest); cur != man.FromVersion {
+		return fmt.Errorf("delta is for version %.12s… but dest has %.12s…", man.FromVersion, cur)
+	}
+	// Manifest paths come straight from the bundle, so they go through
+	// the same rules as archive entry names before touching dest.
+	byPath := make(map[string]deltaFile, len(man.Files))
+	for _, df := range man.Files {
+		clean, err := validateRelPath("delta file", df.Path)
+		if err != nil {
+			return err
+		}
+		if clean == "" || clean == "." {
+			return fmt.Errorf("delta file %q is not a file path", df.Path)
+		}
+		df.Path = clean
+		byPath[clean] = df
+	}
+
+	patched := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read delta bundle: %w", err)
+		}
+		rel := strings.TrimPrefix(filepath.ToSlash(hdr.Name), "patches/")
+		df, ok := byPath[rel]
+		if !ok {
+			return fmt.Errorf("bundle entry %s not listed in delta.json", hdr.Name)
+		}
+		patch, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		if err := patchFile(dest, df, patch); err != nil {
+			return fmt.Errorf("patch %s: %w", df.Path, err)
+		}
+		patched++
+	}
+	if patched != len(man.Files) {
+		return fmt.Errorf("bundle has %d patches but delta.json lists %d files", patched, len(man.Files))
+	}
+	if err := writeVersion(dest, man.ToVersion); err != nil {
+		return err
+	}
+	log.Printf("applied delta: %d files patched, now at %.12s…", patched, man.ToVersion)
+	return nil
+}
+
+// patchFile applies one bsdiff patch to dest/<df.Path>, verifying the
+// old and new hashes around it.
+func patchFile(dest string, df deltaFile, patch []byte) error {
+	target := filepath.Join(dest, filepath.FromSlash(df.Path))
+	if got, err := fileSHA256(target); err != nil {
+		return err
+	} else if got != strings.ToLower(df.OldSHA256) {
+		return fmt.Errorf("current file does not match old_sha256 (got %s)", got)
+	}
+	oldData, err := os.ReadFile(target)
+	if err != nil {
+		return err
+	}
+	newData, err := applyBsdiff(oldData, patch)
+	if err != nil {
+		return err
+	}
+	tmp := target + ".patch-tmp"
+	if err := os.WriteFile(tmp, newData, 0o644); err != nil {
+		return err
+	}
+	if got, err := fileSHA256(tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	} else if got != strings.ToLower(df.NewSHA256) {
+		os.Remove(tmp)
+		return fmt.Errorf("patched file does not match new_sha256 (got %s)", got)
+	}
+	return os.Rename(tmp, target)
+}
+
+// applyBsdiff applies a BSDIFF40 patch (bzip2 control, diff and extra
+// blocks) to oldData, the format produced by Colin Percival's bsdiff.
+func applyBsdiff(oldData, patch []byte) ([]byte, error) {
+	if len(patch) < 32 || string(patch[:8]) != "BSDIFF40" {
+		return nil, fmt.Errorf("not a BSDIFF40 patch")
+	}
+	ctrlLen := bsdiffInt(patch[8:16])
+	diffLen := bsdiffInt(patch[16:24])
+	newSize := bsdiffInt(patch[24:32])
+	if ctrlLen < 0 || diffLen < 0 || newSize < 0 || 32+ctrlLen+diffLen > int64(len(patch)) {
+		return nil, fmt.Errorf("corrupt bsdiff header")
+	}
+	ctrl := bzip2.NewReader(bytes.NewReader(patch[32 : 32+ctrlLen]))
+	diff := bzip2.NewReader(bytes.NewReader(patch[32+ctrlLen : 32+ctrlLen+diffLen]))
+	extra := bzip2.NewReader(bytes.NewReader(patch[32+ctrlLen+diffLen:]))
+
+	newData := make([]byte, newSize)
+	var newPos, oldPos int64
+	var buf [24]byte
+	for newPos < newSize {
+		if _, err := io.ReadFull(ctrl, buf[:]); err != nil {
+			return nil, fmt.Errorf("corrupt bsdiff control block: %w", err)
+		}
+		addLen := bsdiffInt(buf[0:8])
+		copyLen := bsdiffInt(buf[8:16])
+		seek := bsdiffInt(buf[16:24])
+
+		if addLen < 0 || newPos+addLen > newSize {
+			return nil, fmt.Errorf("corrupt bsdiff patch")
+		}
+		if _, err := io.ReadFull(diff, newData[newPos:newPos+addLen]); err != nil {
+			return nil, fmt.Errorf("corrupt bsdiff diff block: %w", err)
+		}
+		for i := int64(0); i < addLen; i++ {
+			if p := oldPos + i; p >= 0 && p < int64(len(oldData)) {
+				newData[newPos+i] += oldData[p]
+			}
+		}
+		newPos += addLen
+		oldPos += addLen
+
+		if copyLen < 0 || newPos+copyLen > newSize {
+			return nil, fmt.Errorf("corrupt bsdiff patch")
+		}
+		if _, err := io.ReadFull(extra, newData[newPos:newPos+copyLen]); err != nil {
+			return nil, fmt.Errorf("corrupt bsdiff extra block: %w", err)
+		}
+		newPos += copyLen
+		oldPos += seek
+	}
+	return newData, nil
+}
+
+// bsdiffInt decodes bsdiff's sign-and-magnitude little-endian int64.
+func bsdiffInt(b []byte) int64 {
+	v := int64(binary.LittleEndian.Uint64(b) &^ (1 << 63))
+	if b[7]&0x80 != 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Permission-denied handling: a raw EACCES on dest usually means one
+// of two things — the directory belongs to another account (left over
+// from a sudo or elevated install) or it sits under an admin-only
+// root like ProgramData or /opt. Instead of a bare "failed to create
+// destination file" fatal, the wrapper says which, and when a
+// terminal is attached offers to relaunch elevated, fall back to a
+// per-user dest, or abort.
+
+// recoverPermissionDenied diagnoses an EACCES on dest and, when
+// interactive, offers recovery. It returns the dest to continue with
+// and whether the install was completed another way.
+func recoverPermissionDenied(ctx context.Context, dest string, cfg *Config, opts syncOptions) (string, bool) {
+	if owner := destOwner(dest); owner != "" {
+		log.Printf("no write access to %s (owned by %s)", dest, owner)
+	} else {
+		log.Printf("no write access to %s", dest)
+	}
+	if underMachineRoot(dest) {
+		log.Printf("%s is under the machine install root and needs administrative rights to modify", dest)
+	}
+	if !stdinInteractive() {
+		log.Printf("re-run elevated, or pass -dest (or -scope user) to install into a writable per-user directory")
+		return "", false
+	}
+	in := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Fprint(os.Stderr, "[e]levate and retry, install to a per-[u]ser directory, or [a]bort? ")
+		line, err := in.ReadString('\n')
+		if err != nil {
+			return "", false
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "e":
+			// The elevated instance only syncs; the launch that may
+			// follow stays in this per-user process.
+			if err := relaunchElevated("-no-launch"); err != nil {
+				log.Printf("elevate: %v", err)
+				return "", false
+			}
+			return dest, true
+		case "u":
+			fallback, err := perUserDest(cfg)
+			if err != nil {
+				log.Printf("per-user destination: %v", err)
+				return "", false
+			}
+			if fallback == dest {
+				log.Printf("the per-user directory is %s itself; pass -dest to pick another location", dest)
+				return "", false
+			}
+			log.Printf("retrying into %s", fallback)
+			if _, err := setupDataDir(fallback, cfg); err != nil {
+				log.Printf("data dir: %v", err)
+				return "", false
+			}
+			if _, err := syncOnce(ctx, fallback, cfg, opts); err != nil {
+				log.Printf("sync: %v", err)
+				return "", false
+			}
+			return fallback, true
+		case "a", "":
+			return "", false
+		}
+	}
+}
+
+// underMachineRoot reports whether path lives under the admin-only
+// machine install root.
+func underMachineRoot(path string) bool {
+	root := machineInstallRoot()
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// perUserDest is the directory ResolveDest would have picked for a
+// user-scope install, used as the fallback when the configured dest
+// is not writable.
+func perUserDest(cfg *Config) (string, error) {
+	if cfg.Name == "" {
+		return "", fmt.Errorf("no -name to derive a per-user directory from")
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(filepath.Join(base, cfg.Name))
+}
+
+// stdinInteractive reports whether a human can answer a prompt:
+// stdin is a character device, not a pipe or file.
+func stdinInteractive() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,28 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"syscall"
+)
+
+// destOwner names the account that owns path, so the denial message
+// can say whose directory is in the way.
+func destOwner(path string) string {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+	uid := fmt.Sprintf("%d", st.Uid)
+	if u, err := user.LookupId(uid); err == nil {
+		return u.Username
+	}
+	return "uid " + uid
+}
@@ -0,0 +1,26 @@
+//go:build windows
+
+package main
+
+import "golang.org/x/sys/windows"
+
+// destOwner names the account that owns path, so the denial message
+// can say whose directory is in the way.
+func destOwner(path string) string {
+	sd, err := windows.GetNamedSecurityInfo(path, windows.SE_FILE_OBJECT, windows.OWNER_SECURITY_INFORMATION)
+	if err != nil {
+		return ""
+	}
+	sid, _, err := sd.Owner()
+	if err != nil || sid == nil {
+		return ""
+	}
+	account, domain, _, err := sid.LookupAccount("")
+	if err != nil {
+		return sid.String()
+	}
+	if domain != "" {
+		return domain + `\` + account
+	}
+	return account
+}
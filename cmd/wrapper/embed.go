@@ -0,0 +1,23 @@
+package main
+
+import (
+	"embed"
+)
+
+// A branded wrapper drops its default wrapper.config.json (and any
+// other assets) into branding/ before building; the stock tree ships
+// the directory empty apart from its README. An external config next
+// to the executable always beats the embedded one, so branding sets
+// defaults without taking away overrides.
+
+//go:embed branding
+var brandingFS embed.FS
+
+// brandedFile returns an embedded branding asset by name.
+func brandedFile(name string) ([]byte, bool) {
+	data, err := brandingFS.ReadFile("branding/" + name)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
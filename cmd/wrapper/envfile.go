@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/launcher"
+)
+
+// A .env file lets per-machine settings (license paths, local ports,
+// feature toggles) live next to the install instead of inside the
+// synced payload, where the next sync would clobber them. Values
+// support the same {dest}/{name}/{entry}/{version} placeholders as
+// registry templates.
+
+// loadEnvFile reads the configured env file — or dest/.env when none
+// is configured — and adds its entries to the child environment. A
+// missing default file is fine; a missing configured file is an
+// error, since the user asked for it.
+func loadEnvFile(dest string, cfg *Config) error {
+	path := cfg.EnvFile
+	configured := path != ""
+	if !configured {
+		path = ".env"
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dest, filepath.FromSlash(path))
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if !configured && os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("env file: %w", err)
+	}
+	defer f.Close()
+
+	n := 0
+	sc := bufio.NewScanner(f)
+	for lineno := 1; sc.Scan(); lineno++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, val, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("env file %s:%d: not a KEY=value line", path, lineno)
+		}
+		val = strings.TrimSpace(val)
+		if len(val) >= 2 && (val[0] == '"' || val[0] == '\'') && val[len(val)-1] == val[0] {
+			val = val[1 : len(val)-1]
+		}
+		launcher.AppendEnv(key + "=" + expandRegTemplate(val, dest, cfg))
+		n++
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("env file %s: %w", path, err)
+	}
+	if n > 0 {
+		log.Printf("loaded %d variables from %s", n, path)
+	}
+	return nil
+}
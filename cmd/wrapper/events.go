@@ -0,0 +1,46 @@
+package main
+
+import (
+	"log"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/syncer"
+)
+
+// cliEvents is the binary's progress sink for the engine: failures
+// and phase changes go to the log as they happen (the engine no
+// longer logs on its own); per-file output needs -v.
+type cliEvents struct{}
+
+func (cliEvents) OnPhaseChange(phase string)         { log.Printf("phase: %s", phase) }
+func (cliEvents) OnFileStart(rel string, size int64) {}
+
+func (cliEvents) OnFileDone(rel string, size int64, err error) {
+	if err == nil && verbosity >= 1 {
+		log.Printf("copied %s (%d bytes)", rel, size)
+	}
+}
+
+func (cliEvents) OnError(err error) {
+	log.Printf("sync: %v", err)
+}
+
+// verbosity is 0 by default, 1 with -v, 2 with -vv.
+var verbosity int
+
+// setVerbosity maps the flags onto the engine's debug sink: level 1
+// explains per-file skips, level 2 adds hash comparisons and retry
+// attempts.
+func setVerbosity(n int) {
+	verbosity = n
+	if n > 0 {
+		syncer.SetDebug(func(level int, format string, args ...any) {
+			if level <= verbosity {
+				log.Printf(format, args...)
+			}
+		})
+	}
+}
+
+func init() {
+	syncer.SetEvents(cliEvents{})
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/errcode"
+
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Export/import moves an install's bookkeeping between machines: the
+// registry record, the dest state (migrations, first run), the change
+// manifest and the protection settings. The payload itself is not
+// bundled — the importing machine re-syncs it from the source — so
+// the file stays small enough to mail around.
+
+// installExport is the bundle format.
+type installExport struct {
+	ExportedAt time.Time       `json:"exported_at"`
+	Host       string          `json:"host"`
+	Record     *installRecord  `json:"record,omitempty"`
+	State      *destState      `json:"state,omitempty"`
+	Changes    *changeManifest `json:"changes,omitempty"`
+	Protected  []string        `json:"protected,omitempty"`
+}
+
+// handleExport implements "wrapper export [-out file]".
+func handleExport(cfg *Config, args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	out := fs.String("out", "", "output file (default <name>.wrapper-export.json)")
+	fs.StringVar(&cfg.Name, "name", cfg.Name, "install to export")
+	fs.BoolVar(&jsonOut, "json", jsonOut, jsonFlagHelp)
+	fs.Parse(args)
+	dest, err := cfg.ResolveDest()
+	if err != nil {
+		fatal("resolve destination", errcode.Wrap(errcode.ConfigInvalid, err))
+	}
+
+	bundle := installExport{
+		ExportedAt: time.Now(),
+		Protected:  cfg.Protected,
+	}
+	bundle.Host, _ = os.Hostname()
+	if cfg.Name != "" {
+		bundle.Record = loadRegistry(dest).Installs[cfg.Name]
+	}
+	bundle.State = loadState(dest)
+	loadChanges(dest)
+	if len(changes.Changes) > 0 {
+		bundle.Changes = changes
+	}
+
+	path := *out
+	if path == "" {
+		name := cfg.Name
+		if name == "" {
+			name = filepath.Base(dest)
+		}
+		path = name + ".wrapper-export.json"
+	}
+	data, err := json.MarshalIndent(&bundle, "", "  ")
+	if err != nil {
+		fatal("export", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		fatal("export", err)
+	}
+	log.Printf("exported install state to %s", path)
+	emitJSON(statusJSON{Status: "ok"})
+}
+
+// handleImport implements "wrapper import <file>", seeding the local
+// dest and registry from an export. dest paths inside the bundle are
+// rewritten to this machine's resolved dest.
+func handleImport(cfg *Config, args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	fs.StringVar(&cfg.Name, "name", cfg.Name, "install to import as")
+	fs.BoolVar(&jsonOut, "json", jsonOut, jsonFlagHelp)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fatalUsage("usage: wrapper import [-name NAME] <file>")
+	}
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		fatal("import", err)
+	}
+	var bundle installExport
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		fatal("import: parse "+fs.Arg(0), errcode.Wrap(errcode.ConfigInvalid, err))
+	}
+	dest, err := cfg.ResolveDest()
+	if err != nil {
+		fatal("resolve destination", errcode.Wrap(errcode.ConfigInvalid, err))
+	}
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		fatal("import", err)
+	}
+
+	if bundle.Record != nil {
+		rec := *bundle.Record
+		rec.Dest = dest
+		if cfg.Name != "" {
+			rec.Name = cfg.Name
+		}
+		reg := loadRegistry(dest)
+		reg.Installs[rec.Name] = &rec
+		if err := reg.save(dest); err != nil {
+			fatal("import: registry", err)
+		}
+	}
+	if bundle.State != nil {
+		if err := saveState(dest, bundle.State); err != nil {
+			fatal("import: state", err)
+		}
+	}
+	if bundle.Changes != nil {
+		changes = bundle.Changes
+		if err := saveChanges(dest); err != nil {
+			fatal("import: change manifest", err)
+		}
+	}
+	if len(bundle.Protected) > 0 && !jsonOut {
+		fmt.Printf("imported protection settings; add to wrapper.config.json: \"protected\": %s\n",
+			mustJSON(bundle.Protected))
+	}
+	log.Printf("imported install state from %s into %s (run a sync to fetch the payload)", fs.Arg(0), dest)
+	emitJSON(importResult{Status: "ok", Dest: dest, Protected: bundle.Protected})
+}
+
+// importResult is the import subcommand's output under -json; the
+// protection settings still need to be copied into the config by hand.
+type importResult struct {
+	Status    string   `json:"status"`
+	Dest      string   `json:"dest"`
+	Protected []string `json:"protected,omitempty"`
+}
+
+func mustJSON(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
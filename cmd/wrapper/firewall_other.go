@@ -0,0 +1,14 @@
+//go:build !windows || minimal
+
+package main
+
+import "log"
+
+func provisionFirewallRules(dest string, cfg *Config) error {
+	if len(cfg.FirewallRules) > 0 {
+		log.Printf("firewall_rules are only applied on Windows; ignoring")
+	}
+	return nil
+}
+
+func removeFirewallRules(cfg *Config) error { return nil }
@@ -0,0 +1,86 @@
+//go:build windows && !minimal
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Firewall rules are provisioned with netsh advfirewall. Rule changes
+// need administrator rights; when the direct call fails the command
+// is retried once through a UAC prompt.
+
+func firewallRuleName(fr *FirewallRule, cfg *Config) string {
+	if fr.Name != "" {
+		return fr.Name
+	}
+	return fmt.Sprintf("%s %s %d", cfg.Name, strings.ToLower(fr.Protocol), fr.Port)
+}
+
+// provisionFirewallRules creates the configured inbound allow rules
+// for the entry program.
+func provisionFirewallRules(dest string, cfg *Config) error {
+	if len(cfg.FirewallRules) == 0 {
+		return nil
+	}
+	entry := filepath.Join(dest, filepath.FromSlash(cfg.Entry))
+	for i := range cfg.FirewallRules {
+		fr := &cfg.FirewallRules[i]
+		proto := strings.ToUpper(fr.Protocol)
+		if proto != "TCP" && proto != "UDP" {
+			return fmt.Errorf("firewall rule %q: protocol must be tcp or udp", firewallRuleName(fr, cfg))
+		}
+		if fr.Port <= 0 || fr.Port > 65535 {
+			return fmt.Errorf("firewall rule %q: invalid port %d", firewallRuleName(fr, cfg), fr.Port)
+		}
+		args := []string{
+			"advfirewall", "firewall", "add", "rule",
+			"name=" + firewallRuleName(fr, cfg),
+			"dir=in", "action=allow",
+			"program=" + entry,
+			"protocol=" + proto,
+			fmt.Sprintf("localport=%d", fr.Port),
+		}
+		if err := runNetsh(args); err != nil {
+			return fmt.Errorf("firewall rule %q: %w", firewallRuleName(fr, cfg), err)
+		}
+		recordChange("firewall_rule", firewallRuleName(fr, cfg))
+		log.Printf("provisioned firewall rule %q", firewallRuleName(fr, cfg))
+	}
+	return nil
+}
+
+// removeFirewallRules deletes the provisioned rules at uninstall.
+func removeFirewallRules(cfg *Config) error {
+	for i := range cfg.FirewallRules {
+		fr := &cfg.FirewallRules[i]
+		args := []string{"advfirewall", "firewall", "delete", "rule", "name=" + firewallRuleName(fr, cfg)}
+		if err := runNetsh(args); err != nil {
+			log.Printf("remove firewall rule %q: %v", firewallRuleName(fr, cfg), err)
+		}
+	}
+	return nil
+}
+
+// runNetsh executes netsh, retrying once elevated when access is
+// denied.
+func runNetsh(args []string) error {
+	out, err := exec.Command("netsh", args...).CombinedOutput()
+	if err == nil {
+		return nil
+	}
+	quoted := make([]string, 0, len(args))
+	for _, a := range args {
+		quoted = append(quoted, psQuote(a))
+	}
+	script := fmt.Sprintf("Start-Process -FilePath netsh -ArgumentList @(%s) -Verb RunAs -Wait",
+		strings.Join(quoted, ","))
+	if out2, err2 := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script).CombinedOutput(); err2 != nil {
+		return fmt.Errorf("netsh failed (%v: %s) and elevation failed: %v\n%s", err, out, err2, out2)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"log"
+	"time"
+)
+
+// First-run handling: the first time a dest is launched, declared
+// setup actions run once (seed a default config, create data dirs,
+// show a EULA via a payload script) and the entry program gets
+// WRAPPER_FIRST_RUN=1 so it can do its own onboarding.
+
+// handleFirstRun runs the one-time setup if this dest has never been
+// launched and reports whether this launch is the first.
+func handleFirstRun(dest string, cfg *Config) bool {
+	st := loadState(dest)
+	if !st.FirstRunAt.IsZero() {
+		return false
+	}
+	for i := range cfg.FirstRun {
+		a := &cfg.FirstRun[i]
+		if err := applyAction(dest, a.Run, a.Action, a.Path); err != nil {
+			log.Printf("first-run action %d: %v", i, err)
+		}
+	}
+	st.FirstRunAt = time.Now()
+	if err := saveState(dest, st); err != nil {
+		log.Printf("record first run: %v", err)
+	}
+	return true
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/errcode"
+
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GC reclaims the space the pipeline leaves behind on purpose —
+// resumable partials, staged versions, extracted archives, shared
+// cache versions — once it is clearly no longer worth keeping. It
+// runs as an explicit "wrapper gc" and opportunistically after every
+// successful sync.
+
+// gcKeepDefault is how many recent versions survive without -keep.
+const gcKeepDefault = 2
+
+// partialMaxAge is how long an interrupted download stays resumable
+// before GC considers it abandoned.
+const partialMaxAge = 7 * 24 * time.Hour
+
+// handleGC implements "wrapper gc [-keep N] [-dry-run]".
+func handleGC(cfg *Config, args []string) {
+	fs := flag.NewFlagSet("gc", flag.ExitOnError)
+	keep := fs.Int("keep", gcKeepDefault, "number of recent cached versions to keep")
+	dryRun := fs.Bool("dry-run", false, "report what would be removed without removing it")
+	fs.StringVar(&cfg.Name, "name", cfg.Name, "install to collect")
+	fs.BoolVar(&jsonOut, "json", jsonOut, jsonFlagHelp)
+	fs.Parse(args)
+	dest, err := cfg.ResolveDest()
+	if err != nil {
+		fatal("resolve destination", errcode.Wrap(errcode.ConfigInvalid, err))
+	}
+	reclaimed := gcDest(dest, cfg, *keep, *dryRun)
+	verb := "reclaimed"
+	if *dryRun {
+		verb = "would reclaim"
+	}
+	log.Printf("gc %s %d bytes", verb, reclaimed)
+	emitJSON(gcResult{Status: "ok", Reclaimed: reclaimed, DryRun: *dryRun})
+}
+
+// gcResult is the gc subcommand's output under -json.
+type gcResult struct {
+	Status    string `json:"status"`
+	Reclaimed int64  `json:"reclaimed_bytes"`
+	DryRun    bool   `json:"dry_run,omitempty"`
+}
+
+// gcOpportunistic runs after a successful sync with defaults and only
+// speaks up when it found something.
+func gcOpportunistic(dest string, cfg *Config) {
+	if reclaimed := gcDest(dest, cfg, gcKeepDefault, false); reclaimed > 0 {
+		log.Printf("gc reclaimed %d bytes", reclaimed)
+	}
+}
+
+// gcDest removes orphaned staging dirs, abandoned partial downloads
+// and cached versions beyond the retention count, returning the bytes
+// freed (or counted, in dry-run mode).
+func gcDest(dest string, cfg *Config, keep int, dryRun bool) int64 {
+	var reclaimed int64
+	remove := func(path string) {
+		size := pathSize(path)
+		if !dryRun {
+			if err := os.RemoveAll(path); err != nil {
+				log.Printf("gc %s: %v", path, err)
+				return
+			}
+		}
+		reclaimed += size
+	}
+
+	// Staging dirs that never completed are dead: a finished stage is
+	// adopted (and deleted) by the next sync, so anything without the
+	// marker was interrupted.
+	if entries, err := os.ReadDir(dest); err == nil {
+		for _, e := range entries {
+			if !e.IsDir() || !strings.HasPrefix(e.Name(), stagingDirPrefix) {
+				continue
+			}
+			dir := filepath.Join(dest, e.Name())
+			if _, err := os.Stat(filepath.Join(dir, completeMarker)); err != nil {
+				remove(dir)
+			}
+		}
+	}
+
+	// Temp files from copies that never reached their rename — a hard
+	// kill or a full disk mid-write. Live syncs remove their own, so
+	// anything older than a few minutes is an orphan.
+	filepath.Walk(dest, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		base := filepath.Base(path)
+		if strings.HasPrefix(base, ".wrapper-tmp-") &&
+			time.Since(info.ModTime()) > 10*time.Minute {
+			remove(path)
+		}
+		// Chunked-copy partials stay resumable as long as download
+		// partials do.
+		if (strings.HasSuffix(base, chunkPartialSuffix) || strings.HasSuffix(base, chunkJournalSuffix)) &&
+			time.Since(info.ModTime()) > partialMaxAge {
+			remove(path)
+		}
+		return nil
+	})
+
+	// Abandoned partials in the download cache, and completed
+	// downloads/extractions beyond the newest `keep`.
+	downloads := filepath.Join(dest, downloadsDirName)
+	if entries, err := os.ReadDir(downloads); err == nil {
+		type aged struct {
+			path string
+			mod  time.Time
+		}
+		var kept []aged
+		for _, e := range entries {
+			path := filepath.Join(downloads, e.Name())
+			info, err := e.Info()
+			if err != nil {
+				continue
+			}
+			if strings.HasSuffix(e.Name(), ".partial") {
+				if time.Since(info.ModTime()) > partialMaxAge {
+					remove(path)
+					remove(strings.TrimSuffix(path, ".partial") + ".etag")
+				}
+				continue
+			}
+			if strings.HasSuffix(e.Name(), ".etag") {
+				continue
+			}
+			kept = append(kept, aged{path, info.ModTime()})
+		}
+		sort.Slice(kept, func(a, b int) bool { return kept[a].mod.After(kept[b].mod) })
+		for _, old := range kept[min(keep, len(kept)):] {
+			remove(old.path)
+		}
+	}
+
+	// Shared cache versions beyond retention, never the one installed.
+	if cfg.SharedCache && cfg.Name != "" {
+		current := readVersion(dest)
+		cacheRoot := filepath.Join(machineInstallRoot(), "wrapper-cache", cfg.Name)
+		if entries, err := os.ReadDir(cacheRoot); err == nil {
+			type aged struct {
+				path string
+				mod  time.Time
+			}
+			var versions []aged
+			for _, e := range entries {
+				if !e.IsDir() || e.Name() == current {
+					continue
+				}
+				info, err := e.Info()
+				if err != nil {
+					continue
+				}
+				versions = append(versions, aged{filepath.Join(cacheRoot, e.Name()), info.ModTime()})
+			}
+			sort.Slice(versions, func(a, b int) bool { return versions[a].mod.After(versions[b].mod) })
+			for _, old := range versions[min(keep, len(versions)):] {
+				remove(old.path)
+			}
+		}
+	}
+	return reclaimed
+}
+
+// pathSize totals a file or tree.
+func pathSize(path string) int64 {
+	var total int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,280 @@
+//go:build !minimal
+
+package main
+
+import (
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/syncer"
+
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// GUI mode: end users who double-click the wrapper get a wizard —
+// destination picker, live progress, a finish-and-launch checkbox —
+// instead of a console that vanishes. Rather than binding a native
+// toolkit per platform, the wizard is one HTML page served on a
+// loopback socket and opened in the default browser; it drives the
+// same engine as the CLI through the syncer.Events progress sink.
+
+// guiProgress is the state the page polls; file callbacks arrive from
+// the parallel copy workers, so counters are atomics.
+type guiProgress struct {
+	phase   atomic.Value // string
+	files   atomic.Int64
+	bytes   atomic.Int64
+	current atomic.Value // string
+
+	mu       sync.Mutex
+	done     bool
+	launched bool
+	errMsg   string
+}
+
+func (p *guiProgress) OnPhaseChange(phase string) { p.phase.Store(phase) }
+func (p *guiProgress) OnFileStart(rel string, size int64) {
+	p.current.Store(rel)
+}
+func (p *guiProgress) OnFileDone(rel string, size int64, err error) {
+	if err == nil {
+		p.files.Add(1)
+		p.bytes.Add(size)
+	}
+}
+func (p *guiProgress) OnError(err error) { log.Printf("sync: %v", err) }
+
+func (p *guiProgress) finish(launched bool, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done = true
+	p.launched = launched
+	if err != nil {
+		p.errMsg = err.Error()
+	}
+}
+
+func (p *guiProgress) snapshot() map[string]any {
+	phase, _ := p.phase.Load().(string)
+	current, _ := p.current.Load().(string)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return map[string]any{
+		"phase":    phase,
+		"files":    p.files.Load(),
+		"bytes":    p.bytes.Load(),
+		"current":  current,
+		"done":     p.done,
+		"launched": p.launched,
+		"error":    p.errMsg,
+	}
+}
+
+// runGUI serves the wizard and blocks until the install (and optional
+// launch) finished and the page saw the final state.
+func runGUI(ctx context.Context, cfg *Config, opts syncOptions, entryArgs []string) error {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("gui: %w", err)
+	}
+	defer ln.Close()
+
+	progress := &guiProgress{}
+	syncer.SetEvents(progress)
+	defaultDest, err := cfg.ResolveDest()
+	if err != nil {
+		defaultDest = ""
+	}
+
+	// A JSON POST to loopback is a CSRF "simple request": while the
+	// wizard is open, any web page could trigger an install. The page
+	// gets a per-run token (unreadable cross-origin) and must echo it
+	// in a header on every API call.
+	token, err := guiToken()
+	if err != nil {
+		return fmt.Errorf("gui: %w", err)
+	}
+	authed := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("X-Wrapper-Token") != token {
+			http.Error(w, "missing or wrong session token", http.StatusForbidden)
+			return false
+		}
+		return true
+	}
+
+	var startOnce sync.Once
+	finished := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprintf(w, guiPage, notifyTitle(cfg), defaultDest, cfg.Entry, token)
+	})
+	mux.HandleFunc("/install", func(w http.ResponseWriter, r *http.Request) {
+		if !authed(w, r) {
+			return
+		}
+		if r.Method != http.MethodPost {
+			http.Error(w, "POST only", http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			Dest   string `json:"dest"`
+			Launch bool   `json:"launch"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		startOnce.Do(func() {
+			go func() {
+				defer close(finished)
+				launched, err := guiInstall(ctx, req.Dest, req.Launch, cfg, opts, entryArgs)
+				progress.finish(launched, err)
+			}()
+		})
+	})
+	mux.HandleFunc("/progress", func(w http.ResponseWriter, r *http.Request) {
+		if !authed(w, r) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(progress.snapshot())
+	})
+
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	url := "http://" + ln.Addr().String() + "/"
+	if err := openBrowser(url); err != nil {
+		log.Printf("open a browser at %s to continue (auto-open failed: %v)", url, err)
+	} else {
+		log.Printf("install wizard at %s", url)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-finished:
+	}
+	// Leave the server up briefly so the page's final poll sees done.
+	time.Sleep(2 * time.Second)
+	progress.mu.Lock()
+	defer progress.mu.Unlock()
+	if progress.errMsg != "" {
+		return fmt.Errorf("install: %s", progress.errMsg)
+	}
+	return nil
+}
+
+// guiToken generates the per-run session secret the page must echo
+// back on API calls.
+func guiToken() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// guiInstall runs the same steps the CLI path does for the chosen
+// destination and reports whether the entry program was launched.
+func guiInstall(ctx context.Context, dest string, launch bool, cfg *Config, opts syncOptions, entryArgs []string) (bool, error) {
+	if dest != "" {
+		cfg.Dest = dest
+	}
+	resolved, err := cfg.ResolveDest()
+	if err != nil {
+		return false, err
+	}
+	crashDest = resolved
+	if _, err := setupDataDir(resolved, cfg); err != nil {
+		return false, err
+	}
+	if _, err := syncOnce(ctx, resolved, cfg, opts); err != nil {
+		return false, err
+	}
+	if !launch || (cfg.Entry == "" && len(cfg.Entries) == 0) {
+		return false, nil
+	}
+	if len(cfg.Entries) > 0 {
+		_, err = launchGroup(ctx, resolved, cfg, entryArgs)
+	} else {
+		_, err = startDetached(resolved, cfg, entryArgs)
+	}
+	return err == nil, err
+}
+
+// openBrowser opens url with the platform's default handler.
+func openBrowser(url string) error {
+	switch runtime.GOOS {
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	case "darwin":
+		return exec.Command("open", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
+
+// guiPage is the whole wizard; placeholders are the window title, the
+// default destination, the entry program (empty hides the launch
+// checkbox) and the session token.
+const guiPage = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>%[1]s Setup</title>
+<style>
+body{font-family:system-ui,sans-serif;max-width:34em;margin:3em auto;padding:0 1em}
+input[type=text]{width:100%%;padding:.4em;margin:.3em 0 1em}
+button{padding:.5em 1.5em}
+#bar{height:1em;background:#ddd;border-radius:.5em;overflow:hidden;margin:1em 0}
+#fill{height:100%%;width:0;background:#3a76d0;transition:width .3s}
+#status{color:#555;min-height:1.5em}
+.err{color:#b00}
+</style></head><body>
+<h1>%[1]s Setup</h1>
+<div id="pick">
+<label>Install to:<input type="text" id="dest" value="%[2]s"></label>
+<label id="launchrow"><input type="checkbox" id="launch" checked> Launch when finished</label>
+<p><button id="go">Install</button></p>
+</div>
+<div id="run" style="display:none">
+<div id="bar"><div id="fill"></div></div>
+<div id="status">Starting…</div>
+</div>
+<script>
+var entry=%[3]q;
+var token=%[4]q;
+if(!entry)document.getElementById('launchrow').style.display='none';
+document.getElementById('go').onclick=function(){
+  document.getElementById('pick').style.display='none';
+  document.getElementById('run').style.display='';
+  fetch('/install',{method:'POST',headers:{'X-Wrapper-Token':token},body:JSON.stringify({
+    dest:document.getElementById('dest').value,
+    launch:document.getElementById('launch').checked})});
+  poll();
+};
+function poll(){
+  fetch('/progress',{headers:{'X-Wrapper-Token':token}}).then(function(r){return r.json()}).then(function(p){
+    var s=document.getElementById('status');
+    if(p.done){
+      document.getElementById('fill').style.width='100%%';
+      if(p.error){s.className='err';s.textContent='Install failed: '+p.error;}
+      else s.textContent=p.launched?'Done — application launched.':'Done.';
+      return;
+    }
+    document.getElementById('fill').style.width=Math.min(95,p.files)+'%%';
+    s.textContent=(p.phase||'preparing')+': '+p.files+' files'+(p.current?' — '+p.current:'');
+    setTimeout(poll,300);
+  }).catch(function(){setTimeout(poll,1000)});
+}
+</script></body></html>
+`
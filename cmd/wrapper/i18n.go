@@ -0,0 +1,216 @@
+package main
+
+import "strings"
+
+// Localization: the feed's primary user base reads Chinese, the
+// maintainers read English, and nobody wants a translation framework
+// in an installer. The catalog is a map from the English message —
+// which doubles as the fallback — to its translations, selected once
+// at startup from the OS UI language or the -lang override.
+
+// lang is the active language tag ("en-US", "zh-CN"); en-US means
+// every tr call falls through to its argument.
+var lang = "en-US"
+
+// initLocale picks the language: the -lang override wins, then the
+// OS UI language, then English. Tags are matched by primary subtag,
+// so "zh-TW" and "zh" both select the zh-CN catalog for now.
+func initLocale(override string) {
+	tag := override
+	if tag == "" {
+		tag = detectLocale()
+	}
+	switch family(tag) {
+	case "zh":
+		lang = "zh-CN"
+	default:
+		lang = "en-US"
+	}
+}
+
+// family reduces a locale tag to its primary language subtag:
+// "zh_CN.UTF-8" and "zh-TW" both become "zh".
+func family(tag string) string {
+	tag = strings.ToLower(tag)
+	for i, r := range tag {
+		if r == '-' || r == '_' || r == '.' {
+			return tag[:i]
+		}
+	}
+	return tag
+}
+
+// tr returns the active translation of msg, or msg itself when none
+// exists — an untranslated string degrades to English, never to a key.
+func tr(msg string) string {
+	if t, ok := catalog[msg][lang]; ok {
+		return t
+	}
+	return msg
+}
+
+// peekArg finds a flag's value before flag.Parse runs — which is too
+// late for -lang, since -h output must already be localized.
+func peekArg(name string, args []string) string {
+	for i, a := range args {
+		if a == "-"+name || a == "--"+name {
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+			return ""
+		}
+		if v, ok := strings.CutPrefix(a, "-"+name+"="); ok {
+			return v
+		}
+		if v, ok := strings.CutPrefix(a, "--"+name+"="); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// catalog holds the translations, keyed by the English message.
+var catalog = map[string]map[string]string{
+	"Usage: wrapper [flags] [-- entry args...]": {
+		"zh-CN": "用法：wrapper [选项] [-- 入口程序参数...]",
+	},
+	"Syncs a payload directory to a destination and launches its entry program.": {
+		"zh-CN": "将载荷目录同步到目标目录，并启动其入口程序。",
+	},
+	"Flags override values from wrapper.config.json next to the executable.": {
+		"zh-CN": "命令行选项会覆盖可执行文件旁 wrapper.config.json 中的配置。",
+	},
+	"Examples:": {
+		"zh-CN": "示例：",
+	},
+
+	// Flag descriptions, in registration order.
+	"override the UI language (e.g. zh-CN, en-US)": {
+		"zh-CN": "覆盖界面语言（例如 zh-CN、en-US）",
+	},
+	"payload source directory": {
+		"zh-CN": "载荷源目录",
+	},
+	"destination directory (default: per-user dir named by -name)": {
+		"zh-CN": "目标目录（默认：以 -name 命名的用户目录）",
+	},
+	"install name, used for the default destination": {
+		"zh-CN": "安装名称，用于生成默认目标目录",
+	},
+	"entry program to launch, relative to dest": {
+		"zh-CN": "要启动的入口程序，相对于目标目录",
+	},
+	`sync mode: "copy" or "mirror"`: {
+		"zh-CN": `同步模式："copy" 或 "mirror"`,
+	},
+	`install scope: "user" or "machine" (shared dest, elevated sync)`: {
+		"zh-CN": `安装范围："user" 或 "machine"（共享目标目录，同步需提权）`,
+	},
+	`payload symlinks: "materialize", "preserve" or "forbid" (default per-OS)`: {
+		"zh-CN": `载荷符号链接处理："materialize"、"preserve" 或 "forbid"（默认因操作系统而异）`,
+	},
+	"re-copy files even if size and mtime match": {
+		"zh-CN": "即使大小和修改时间一致也重新复制文件",
+	},
+	"ignore the install manifest and sync the complete payload": {
+		"zh-CN": "忽略安装清单，同步完整载荷",
+	},
+	"wait for the entry program and propagate its exit code": {
+		"zh-CN": "等待入口程序退出并传递其退出码",
+	},
+	"with -wait, prefix child output on the console and capture raw streams to dest/.wrapper-logs": {
+		"zh-CN": "与 -wait 配合：控制台输出加前缀，并将原始输出流保存到 dest/.wrapper-logs",
+	},
+	"proxy URL for all network traffic (default: HTTP_PROXY et al.)": {
+		"zh-CN": "所有网络流量使用的代理 URL（默认：HTTP_PROXY 等环境变量）",
+	},
+	"comma-separated path prefixes to skip": {
+		"zh-CN": "要跳过的路径前缀，逗号分隔",
+	},
+	"comma-separated overlay source directories merged over -src (later wins)": {
+		"zh-CN": "叠加在 -src 之上的覆盖源目录，逗号分隔（靠后的优先）",
+	},
+	"comma-separated mirror URLs tried when a URL source is unreachable": {
+		"zh-CN": "源 URL 不可达时尝试的镜像 URL，逗号分隔",
+	},
+	"append log output to this file": {
+		"zh-CN": "将日志追加写入此文件",
+	},
+	"sync only, do not launch the entry program": {
+		"zh-CN": "仅同步，不启动入口程序",
+	},
+	"refuse downloaded payloads with no obtainable SHA-256": {
+		"zh-CN": "拒绝无法获得 SHA-256 校验值的下载载荷",
+	},
+	"apply a delta bundle (path or URL) to dest instead of a full sync": {
+		"zh-CN": "对目标目录应用增量包（路径或 URL），替代完整同步",
+	},
+	"extract remote tarballs while downloading (no byte-level resume)": {
+		"zh-CN": "边下载边解压远程 tar 包（不支持字节级断点续传）",
+	},
+	"after the initial sync, stay resident and resync when the source changes (local directory sources only)": {
+		"zh-CN": "初次同步后驻留，源变化时自动重新同步（仅限本地目录源）",
+	},
+	"quiet period before a watched change triggers a resync": {
+		"zh-CN": "监视到变化后触发重新同步前的静默期",
+	},
+	"stay resident and resync every -interval (for service managers)": {
+		"zh-CN": "驻留并每隔 -interval 重新同步（供服务管理器使用）",
+	},
+	"resync interval in daemon mode": {
+		"zh-CN": "守护模式下的重新同步间隔",
+	},
+	"serve /healthz and /status on this address in daemon mode (e.g. 127.0.0.1:0)": {
+		"zh-CN": "守护模式下在此地址提供 /healthz 和 /status（例如 127.0.0.1:0）",
+	},
+	"export OTLP/HTTP trace spans to this collector base URL": {
+		"zh-CN": "将 OTLP/HTTP 跟踪数据导出到此收集器地址",
+	},
+	"write a CPU profile of this run to a file": {
+		"zh-CN": "将本次运行的 CPU 性能分析写入文件",
+	},
+	"write a heap profile at exit to a file": {
+		"zh-CN": "退出时将堆内存性能分析写入文件",
+	},
+	`update feed: a JSON URL or "github:owner/repo"`: {
+		"zh-CN": `更新源：JSON URL 或 "github:owner/repo"`,
+	},
+	"check the feed for a newer version and exit (10 = update available)": {
+		"zh-CN": "检查更新源是否有新版本后退出（退出码 10 表示有更新）",
+	},
+	"remove the installed payload and its shortcuts, then exit": {
+		"zh-CN": "移除已安装的载荷及其快捷方式后退出",
+	},
+	"serve a graphical install wizard in the browser instead of the console flow": {
+		"zh-CN": "在浏览器中提供图形安装向导，替代控制台流程",
+	},
+	"draw an in-place terminal progress view during the sync": {
+		"zh-CN": "同步期间在终端原地绘制进度视图",
+	},
+	"write JSON progress events, one per line, to this pipe or file": {
+		"zh-CN": "将 JSON 进度事件逐行写入此管道或文件",
+	},
+	"assume yes; skip confirmation prompts before destructive operations": {
+		"zh-CN": "默认同意；跳过破坏性操作前的确认提示",
+	},
+	"write results as JSON to stdout; log output stays on stderr": {
+		"zh-CN": "以 JSON 形式将结果写入标准输出；日志仍输出到标准错误",
+	},
+	"verbose: log per-file copies and skip reasons": {
+		"zh-CN": "详细输出：记录每个文件的复制和跳过原因",
+	},
+	"very verbose: -v plus hash comparisons and retry attempts": {
+		"zh-CN": "更详细输出：在 -v 基础上记录哈希比较和重试过程",
+	},
+
+	// Confirmation prompts.
+	"Proceed? [y/N] ": {
+		"zh-CN": "是否继续？[y/N] ",
+	},
+	"Uninstall will remove %s and the shortcuts, associations and registry entries recorded at install.": {
+		"zh-CN": "卸载将移除 %s 以及安装时记录的快捷方式、文件关联和注册表项。",
+	},
+	"Mirror mode will delete anything in %s that is not in the source, currently including:": {
+		"zh-CN": "镜像模式将删除 %s 中不存在于源目录的所有内容，目前包括：",
+	},
+}
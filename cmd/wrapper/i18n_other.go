@@ -0,0 +1,16 @@
+//go:build !windows
+
+package main
+
+import "os"
+
+// detectLocale reads the usual POSIX locale variables in precedence
+// order; values look like "zh_CN.UTF-8".
+func detectLocale() string {
+	for _, v := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if val := os.Getenv(v); val != "" && val != "C" && val != "POSIX" {
+			return val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestLocaleSelection(t *testing.T) {
+	defer func() { lang = "en-US" }()
+	for _, tc := range []struct{ override, want string }{
+		{"zh-CN", "zh-CN"},
+		{"zh_TW", "zh-CN"},
+		{"en-US", "en-US"},
+		{"fr-FR", "en-US"},
+	} {
+		initLocale(tc.override)
+		if lang != tc.want {
+			t.Errorf("initLocale(%q): lang = %q, want %q", tc.override, lang, tc.want)
+		}
+	}
+	lang = "zh-CN"
+	if tr("Examples:") != "示例：" {
+		t.Errorf("tr(Examples:) = %q", tr("Examples:"))
+	}
+	if tr("no such message") != "no such message" {
+		t.Error("missing catalog entry did not fall back to English")
+	}
+}
+
+func TestPeekArg(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-lang", "zh-CN"}, "zh-CN"},
+		{[]string{"-lang=en-US"}, "en-US"},
+		{[]string{"--lang=zh"}, "zh"},
+		{[]string{"-name", "Aiwb"}, ""},
+	} {
+		if got := peekArg("lang", tc.args); got != tc.want {
+			t.Errorf("peekArg(lang, %v) = %q, want %q", tc.args, got, tc.want)
+		}
+	}
+}
+
+func TestCatalogHasChinese(t *testing.T) {
+	for msg, m := range catalog {
+		if m["zh-CN"] == "" {
+			t.Errorf("no zh-CN translation for %q", msg)
+		}
+	}
+}
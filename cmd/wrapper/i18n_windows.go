@@ -0,0 +1,23 @@
+//go:build windows
+
+package main
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procGetUserDefaultLocaleName = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetUserDefaultLocaleName")
+
+// detectLocale asks Windows for the user's UI locale name, e.g.
+// "zh-CN"; an empty string means detection failed.
+func detectLocale() string {
+	// LOCALE_NAME_MAX_LENGTH is 85.
+	var buf [85]uint16
+	n, _, _ := procGetUserDefaultLocaleName.Call(uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+	if n == 0 {
+		return ""
+	}
+	return windows.UTF16ToString(buf[:])
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/errcode"
+)
+
+// -json is the automation surface: every command writes its results as
+// JSON values to stdout, one per line, and everything meant for humans
+// — the log — stays on stderr. Errors carry their internal/errcode
+// code, so a parent installer branches on the taxonomy instead of
+// parsing free-form log lines.
+
+// jsonOut is detected before flag.Parse because the subcommands
+// (self-test, bench, …) dispatch on os.Args first; the flag is still
+// registered in every flag set so it shows in -h and parses cleanly.
+var jsonOut = peekBool("json", os.Args[1:])
+
+// jsonFlagHelp is the -json description shared by the subcommand flag
+// sets; the main flag set localizes it.
+const jsonFlagHelp = "write results as JSON to stdout; log output stays on stderr"
+
+// peekBool reports whether a boolean flag appears in args — the
+// boolean counterpart of peekArg.
+func peekBool(name string, args []string) bool {
+	for _, a := range args {
+		switch a {
+		case "-" + name, "--" + name, "-" + name + "=true", "--" + name + "=true":
+			return true
+		case "--":
+			return false
+		}
+	}
+	return false
+}
+
+// statusJSON is the object every run ends with under -json.
+type statusJSON struct {
+	Status    string       `json:"status"`              // "ok", "error", "cancelled", "up-to-date", "update-available"
+	Context   string       `json:"context,omitempty"`   // the step that failed
+	Error     string       `json:"error,omitempty"`     // human-readable cause; free to change
+	Code      errcode.Code `json:"code,omitempty"`      // stable failure category
+	Latest    string       `json:"latest,omitempty"`    // -check-update: feed version
+	Installed string       `json:"installed,omitempty"` // -check-update: stamped version
+	Exit      int          `json:"exit_code"`
+}
+
+// emitJSON writes one JSON value to stdout. Without -json it is a
+// no-op, so call sites need no guard.
+func emitJSON(v any) {
+	if !jsonOut {
+		return
+	}
+	json.NewEncoder(os.Stdout).Encode(v)
+}
+
+// emitJSONError is the error-object half of the contract, shared by
+// fatal and the paths that exit without going through it.
+func emitJSONError(context string, err error) {
+	emitJSON(statusJSON{
+		Status:  "error",
+		Context: context,
+		Error:   err.Error(),
+		Code:    errcode.Of(err),
+		Exit:    errcode.ExitCode(err),
+	})
+}
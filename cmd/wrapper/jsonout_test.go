@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestPeekBool(t *testing.T) {
+	cases := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"-json"}, true},
+		{[]string{"--json"}, true},
+		{[]string{"-json=true", "-src", "x"}, true},
+		{[]string{"-src", "x"}, false},
+		{[]string{"--", "-json"}, false}, // belongs to the entry program
+		{nil, false},
+	}
+	for _, c := range cases {
+		if got := peekBool("json", c.args); got != c.want {
+			t.Errorf("peekBool(json, %v) = %v, want %v", c.args, got, c.want)
+		}
+	}
+}
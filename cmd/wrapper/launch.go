@@ -0,0 +1,11 @@
+package main
+
+import "github.com/doudou0720/Aiwb_0Installer_Feed/pkg/launcher"
+
+// Launching lives in pkg/launcher; aliased here like the syncer.
+var (
+	launchEntry   = launcher.Launch
+	launchGroup   = launcher.LaunchGroup
+	startDetached = launcher.StartDetached
+	lastEntryPID  = launcher.LastPID
+)
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Two wrapper instances pointed at one dest (a double-clicked shortcut
+// plus a scheduled sync, say) must not race on the same files. A lock
+// file with the holder's PID and a heartbeat serializes them: the
+// second instance waits briefly for the first to finish, and a lock
+// whose heartbeat stopped — a crashed or killed run — is broken
+// automatically instead of wedging the install forever.
+
+const lockFileName = ".wrapper-lock"
+
+// lockHeartbeat is how often the holder refreshes the lock;
+// lockStale is how old a heartbeat may get before the lock is
+// considered abandoned.
+const (
+	lockHeartbeat = 5 * time.Second
+	lockStale     = 30 * time.Second
+	lockWait      = 30 * time.Second
+)
+
+type destLock struct {
+	path string
+	stop chan struct{}
+	done chan struct{}
+}
+
+// acquireLock takes the per-dest lock, waiting up to lockWait for a
+// live holder to finish.
+func acquireLock(dest string) (*destLock, error) {
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dest, lockFileName)
+	deadline := time.Now().Add(lockWait)
+	logged := false
+	for {
+		if err := writeLockFile(path); err == nil {
+			l := &destLock{path: path, stop: make(chan struct{}), done: make(chan struct{})}
+			go l.heartbeat()
+			return l, nil
+		} else if !os.IsExist(err) {
+			return nil, err
+		}
+		pid, at, ok := readLockFile(path)
+		if !ok || time.Since(at) > lockStale {
+			log.Printf("breaking stale lock on %s (pid %d, last heartbeat %s)", dest, pid, at.Format(time.RFC3339))
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("another wrapper instance (pid %d) is working on %s; try again when it finishes", pid, dest)
+		}
+		if !logged {
+			log.Printf("waiting for wrapper instance pid %d to finish with %s", pid, dest)
+			logged = true
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func writeLockFile(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(f, "%d %d\n", os.Getpid(), time.Now().UnixNano())
+	return f.Close()
+}
+
+func readLockFile(path string) (pid int, at time.Time, ok bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 {
+		return 0, time.Time{}, false
+	}
+	pid, err = strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	nanos, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return 0, time.Time{}, false
+	}
+	return pid, time.Unix(0, nanos), true
+}
+
+// heartbeat refreshes the timestamp until release.
+func (l *destLock) heartbeat() {
+	defer close(l.done)
+	ticker := time.NewTicker(lockHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			os.WriteFile(l.path, []byte(fmt.Sprintf("%d %d\n", os.Getpid(), time.Now().UnixNano())), 0o644)
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+func (l *destLock) release() {
+	close(l.stop)
+	<-l.done
+	os.Remove(l.path)
+}
@@ -0,0 +1,498 @@
+// The wrapper keeps a destination directory in sync with a payload
+// published through a 0install feed and then launches the payload's
+// entry program. It exists so that apps distributed via 0install can
+// run from a stable, writable location instead of the read-only
+// implementation cache.
+//
+// Typical use:
+//
+//	wrapper -src <0install-cache-impl> -dest D:\Apps\Aiwb -entry Aiwb.exe
+//
+// Defaults come from wrapper.config.json next to the executable so a
+// branded copy of the wrapper can be double-clicked with no arguments.
+package main
+
+import (
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/errcode"
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/launcher"
+
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// exitInterrupted is the exit code after a clean Ctrl+C / SIGTERM
+// shutdown, matching the shell convention of 128+SIGINT.
+const exitInterrupted = 130
+
+// exitUpdateAvailable is returned by -check-update when the feed
+// advertises a version different from the installed one, so the entry
+// app (or a script) can react without parsing output.
+const exitUpdateAvailable = 10
+
+// fatal logs err with its classified code and exits with the exit
+// code the contract in internal/errcode assigns to its category.
+func fatal(context string, err error) {
+	emitJSONError(context, err)
+	emitResult("error", "", 0, err)
+	if context != "" {
+		log.Printf("[%s] %s: %v", errcode.Of(err), context, err)
+	} else {
+		log.Printf("[%s] %v", errcode.Of(err), err)
+	}
+	os.Exit(errcode.ExitCode(err))
+}
+
+// fatalUsage reports an invalid configuration or flag combination and
+// exits with the config-error code.
+func fatalUsage(format string, args ...any) {
+	fatal("", errcode.Wrap(errcode.ConfigInvalid, fmt.Errorf(format, args...)))
+}
+
+func usage() {
+	w := flag.CommandLine.Output()
+	fmt.Fprintln(w, tr("Usage: wrapper [flags] [-- entry args...]"))
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, tr("Syncs a payload directory to a destination and launches its entry program."))
+	fmt.Fprintln(w, tr("Flags override values from wrapper.config.json next to the executable."))
+	fmt.Fprintln(w)
+	flag.PrintDefaults()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, tr("Examples:"))
+	fmt.Fprintln(w, "  wrapper -src C:\\cache\\impl -dest D:\\Apps\\Aiwb -entry Aiwb.exe")
+	fmt.Fprintln(w, "  wrapper -name Aiwb -mode mirror -force")
+}
+
+func main() {
+	cfg, err := loadConfig()
+	if err != nil {
+		fatal("config", errcode.Wrap(errcode.ConfigInvalid, err))
+	}
+	defer reportCrashes(cfg)
+
+	if len(os.Args) > 1 && os.Args[1] == "schedule" {
+		handleSchedule(cfg, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "ctl" {
+		handleCtl(cfg, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		handleExport(cfg, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		handleImport(cfg, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "gc" {
+		handleGC(cfg, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "bench" {
+		handleBench(cfg, os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "self-test" {
+		handleSelfTest(cfg, os.Args[2:])
+		return
+	}
+
+	// The language must be settled before any flag descriptions or
+	// usage text render, so -lang is peeked at ahead of flag.Parse.
+	initLocale(peekArg("lang", os.Args[1:]))
+
+	flag.Usage = usage
+	flag.String("lang", "", tr("override the UI language (e.g. zh-CN, en-US)"))
+	flag.StringVar(&cfg.Source, "src", cfg.Source, tr("payload source directory"))
+	flag.StringVar(&cfg.Dest, "dest", cfg.Dest, tr("destination directory (default: per-user dir named by -name)"))
+	flag.StringVar(&cfg.Name, "name", cfg.Name, tr("install name, used for the default destination"))
+	flag.StringVar(&cfg.Entry, "entry", cfg.Entry, tr("entry program to launch, relative to dest"))
+	flag.StringVar(&cfg.Mode, "mode", cfg.Mode, tr(`sync mode: "copy" or "mirror"`))
+	flag.StringVar(&cfg.Scope, "scope", cfg.Scope, tr(`install scope: "user" or "machine" (shared dest, elevated sync)`))
+	flag.StringVar(&cfg.DestSymlinks, "dest-symlinks", cfg.DestSymlinks, tr(`payload symlinks: "materialize", "preserve" or "forbid" (default per-OS)`))
+	flag.BoolVar(&cfg.Force, "force", cfg.Force, tr("re-copy files even if size and mtime match"))
+	flag.BoolVar(&cfg.Full, "full", cfg.Full, tr("ignore the install manifest and sync the complete payload"))
+	flag.BoolVar(&cfg.Wait, "wait", cfg.Wait, tr("wait for the entry program and propagate its exit code"))
+	flag.BoolVar(&cfg.TeeOutput, "tee", cfg.TeeOutput, tr("with -wait, prefix child output on the console and capture raw streams to dest/.wrapper-logs"))
+	flag.StringVar(&cfg.Proxy, "proxy", cfg.Proxy, tr("proxy URL for all network traffic (default: HTTP_PROXY et al.)"))
+	excludeFlag := flag.String("exclude", "", tr("comma-separated path prefixes to skip"))
+	overlayFlag := flag.String("overlay", "", tr("comma-separated overlay source directories merged over -src (later wins)"))
+	mirrorsFlag := flag.String("mirrors", "", tr("comma-separated mirror URLs tried when a URL source is unreachable"))
+	logFile := flag.String("log", "", tr("append log output to this file"))
+	noLaunch := flag.Bool("no-launch", false, tr("sync only, do not launch the entry program"))
+	requireChecksum := flag.Bool("require-checksum", false, tr("refuse downloaded payloads with no obtainable SHA-256"))
+	deltaFlag := flag.String("delta", "", tr("apply a delta bundle (path or URL) to dest instead of a full sync"))
+	streamFlag := flag.Bool("stream", true, tr("extract remote tarballs while downloading (no byte-level resume)"))
+	watchFlag := flag.Bool("watch", false, tr("after the initial sync, stay resident and resync when the source changes (local directory sources only)"))
+	settleFlag := flag.Duration("settle", 2*time.Second, tr("quiet period before a watched change triggers a resync"))
+	daemonFlag := flag.Bool("daemon", false, tr("stay resident and resync every -interval (for service managers)"))
+	intervalFlag := flag.Duration("interval", 6*time.Hour, tr("resync interval in daemon mode"))
+	statusAddr := flag.String("status-addr", "", tr("serve /healthz and /status on this address in daemon mode (e.g. 127.0.0.1:0)"))
+	traceEndpoint := flag.String("trace-endpoint", "", tr("export OTLP/HTTP trace spans to this collector base URL"))
+	cpuProfile := flag.String("pprof-cpu", "", tr("write a CPU profile of this run to a file"))
+	memProfile := flag.String("pprof-mem", "", tr("write a heap profile at exit to a file"))
+	flag.StringVar(&cfg.Feed, "feed", cfg.Feed, tr(`update feed: a JSON URL or "github:owner/repo"`))
+	checkUpdateFlag := flag.Bool("check-update", false, tr("check the feed for a newer version and exit (10 = update available)"))
+	uninstallFlag := flag.Bool("uninstall", false, tr("remove the installed payload and its shortcuts, then exit"))
+	guiFlag := flag.Bool("gui", false, tr("serve a graphical install wizard in the browser instead of the console flow"))
+	tuiFlag := flag.Bool("tui", false, tr("draw an in-place terminal progress view during the sync"))
+	progressPipeFlag := flag.String("progress-pipe", "", tr("write JSON progress events, one per line, to this pipe or file"))
+	flag.BoolVar(&assumeYes, "y", false, tr("assume yes; skip confirmation prompts before destructive operations"))
+	flag.BoolVar(&jsonOut, "json", jsonOut, tr("write results as JSON to stdout; log output stays on stderr"))
+	vFlag := flag.Bool("v", false, tr("verbose: log per-file copies and skip reasons"))
+	vvFlag := flag.Bool("vv", false, tr("very verbose: -v plus hash comparisons and retry attempts"))
+	flag.Parse()
+
+	switch {
+	case *vvFlag:
+		setVerbosity(2)
+	case *vFlag:
+		setVerbosity(1)
+	}
+
+	if *logFile != "" {
+		f, err := os.OpenFile(*logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			fatal("open log file", errcode.Wrap(errcode.ConfigInvalid, err))
+		}
+		defer f.Close()
+		log.SetOutput(f)
+	}
+	if *excludeFlag != "" {
+		for _, ex := range strings.Split(*excludeFlag, ",") {
+			if ex = strings.TrimSpace(ex); ex != "" {
+				cfg.Excludes = append(cfg.Excludes, ex)
+			}
+		}
+	}
+	if *overlayFlag != "" {
+		for _, o := range strings.Split(*overlayFlag, ",") {
+			if o = strings.TrimSpace(o); o != "" {
+				cfg.Overlays = append(cfg.Overlays, o)
+			}
+		}
+	}
+	if *mirrorsFlag != "" {
+		cfg.Mirrors = nil
+		for _, m := range strings.Split(*mirrorsFlag, ",") {
+			if m = strings.TrimSpace(m); m != "" {
+				cfg.Mirrors = append(cfg.Mirrors, m)
+			}
+		}
+	}
+	if err := validateConfigPaths(cfg); err != nil {
+		fatal("config", errcode.Wrap(errcode.ConfigInvalid, err))
+	}
+	if err := initProxy(cfg.Proxy); err != nil {
+		fatal("proxy", errcode.Wrap(errcode.ConfigInvalid, err))
+	}
+	if *traceEndpoint != "" {
+		initTracing(*traceEndpoint)
+	}
+	if err := initProfiles(*cpuProfile, *memProfile); err != nil {
+		fatal("profile", errcode.Wrap(errcode.ConfigInvalid, err))
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = "copy"
+	}
+	if cfg.Mode != "copy" && cfg.Mode != "mirror" {
+		fatalUsage("invalid -mode %q: want copy or mirror", cfg.Mode)
+	}
+	if cfg.Scope == "" {
+		cfg.Scope = "user"
+	}
+	if cfg.Scope != "user" && cfg.Scope != "machine" {
+		fatalUsage("invalid -scope %q: want user or machine", cfg.Scope)
+	}
+	if *uninstallFlag {
+		dest, err := cfg.ResolveDest()
+		if err != nil {
+			fatal("resolve destination", errcode.Wrap(errcode.ConfigInvalid, err))
+		}
+		if !confirmRemoval(fmt.Sprintf(tr("Uninstall will remove %s and the shortcuts, associations and registry entries recorded at install."), dest), nil) {
+			log.Printf("uninstall cancelled")
+			emitJSON(statusJSON{Status: "cancelled"})
+			emitResult("cancelled", "", 0, nil)
+			return
+		}
+		lock, err := acquireLock(dest)
+		if err != nil {
+			fatal("lock", errcode.Wrap(errcode.LockHeld, err))
+		}
+		// Prefer the recorded change manifest: it reflects what was
+		// actually created, even if the config changed since, and is
+		// replayed newest-first. Without one, fall back to removing
+		// whatever the current config declares.
+		if !undoChanges(dest, cfg) {
+			if err := removeShortcuts(cfg); err != nil {
+				log.Printf("remove shortcuts: %v", err)
+			}
+			if err := unregisterFileAssociations(cfg); err != nil {
+				log.Printf("remove file associations: %v", err)
+			}
+			if err := unregisterProtocols(cfg); err != nil {
+				log.Printf("remove protocols: %v", err)
+			}
+			if err := removeAppsEntry(cfg); err != nil {
+				log.Printf("remove apps entry: %v", err)
+			}
+			if err := removeRegistryKeys(dest, cfg); err != nil {
+				log.Printf("remove registry keys: %v", err)
+			}
+			if err := unregisterAutostart(cfg); err != nil {
+				log.Printf("remove autostart: %v", err)
+			}
+			if err := removeAppBundle(cfg); err != nil {
+				log.Printf("remove app bundle: %v", err)
+			}
+			if err := unregisterContextMenu(cfg); err != nil {
+				log.Printf("remove context menu: %v", err)
+			}
+			if err := unregisterPath(dest, cfg); err != nil {
+				log.Printf("remove path registration: %v", err)
+			}
+			if err := removeFirewallRules(cfg); err != nil {
+				log.Printf("remove firewall rules: %v", err)
+			}
+		}
+		lock.release()
+		if err := removePayload(dest, cfg); err != nil {
+			fatal("remove "+dest, err)
+		}
+		unregisterInstall(dest, cfg)
+		log.Printf("uninstalled %s", dest)
+		emitJSON(statusJSON{Status: "ok"})
+		emitResult("ok", "", 0, nil)
+		return
+	}
+
+	if *checkUpdateFlag {
+		if cfg.Feed == "" {
+			fatalUsage("-check-update needs -feed or \"feed\" in the config")
+		}
+		dest, err := cfg.ResolveDest()
+		if err != nil {
+			fatal("resolve destination", errcode.Wrap(errcode.ConfigInvalid, err))
+		}
+		latest, pending, err := checkUpdate(dest, cfg.Feed)
+		if err != nil {
+			fatal("check update", errcode.Wrap(errcode.DownloadFailed, err))
+		}
+		if !pending {
+			log.Printf("%s is up to date (%.12s…)", dest, latest.Version)
+			emitJSON(statusJSON{Status: "up-to-date", Latest: latest.Version, Installed: readVersion(dest)})
+			emitResult("ok", "", 0, nil)
+			return
+		}
+		log.Printf("update available: %.12s… (installed %.12s…)", latest.Version, readVersion(dest))
+		if latest.Notes != "" {
+			log.Printf("release notes: %s", latest.Notes)
+		}
+		emitJSON(statusJSON{Status: "update-available", Latest: latest.Version, Installed: readVersion(dest), Exit: exitUpdateAvailable})
+		emitResult("update-available", "", 0, nil)
+		os.Exit(exitUpdateAvailable)
+	}
+
+	if *watchFlag && (isURL(cfg.Source) || isArchive(cfg.Source)) {
+		fatalUsage("-watch needs a local directory source, not %q", cfg.Source)
+	}
+	if cfg.Source == "" && *deltaFlag == "" {
+		flag.Usage()
+		fatalUsage("no source configured; pass -src or fill wrapper.config.json")
+	}
+
+	dest, err := cfg.ResolveDest()
+	if err != nil {
+		fatal("resolve destination", errcode.Wrap(errcode.ConfigInvalid, err))
+	}
+	crashDest = dest
+
+	// Ctrl+C or SIGTERM cancels the context; in-flight downloads and
+	// copy workers stop, deferred cleanup (locks, partial markers) runs,
+	// and the wrapper exits with a distinct code instead of dying
+	// mid-write.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	entryArgs := openWithArgs(flag.Args())
+
+	if *guiFlag {
+		if err := runGUI(ctx, cfg, syncOptions{requireChecksum: *requireChecksum, stream: *streamFlag}, entryArgs); err != nil {
+			fatal("gui", err)
+		}
+		emitResult("ok", "", 0, nil)
+		return
+	}
+
+	if *deltaFlag != "" {
+		bundle := *deltaFlag
+		if isURL(bundle) {
+			if bundle, err = downloadSource(ctx, bundle, dest); err != nil {
+				fatal("download delta", errcode.Wrap(errcode.DownloadFailed, err))
+			}
+		}
+		if err := applyDeltaBundle(bundle, dest); err != nil {
+			fatal("delta", errcode.Wrap(errcode.SyncFailed, err))
+		}
+		if *noLaunch || cfg.Entry == "" {
+			emitJSON(statusJSON{Status: "ok"})
+			emitResult("ok", "", 0, nil)
+			return
+		}
+		code, err := launchEntry(dest, cfg, entryArgs)
+		if err != nil {
+			fatal("launch", err)
+		}
+		emitJSON(statusJSON{Status: "ok", Exit: code})
+		if cfg.Wait {
+			emitResult("ok", "waited", code, nil)
+		} else {
+			emitResult("ok", "started", lastEntryPID(), nil)
+		}
+		os.Exit(code)
+	}
+
+	// A first mirror sync into a directory that already has content is
+	// the case that used to delete files silently; an install we
+	// stamped ourselves needs no warning.
+	if cfg.Mode == "mirror" && readVersion(dest) == "" {
+		if extras := destPreview(dest); len(extras) > 0 {
+			if !confirmRemoval(fmt.Sprintf(tr("Mirror mode will delete anything in %s that is not in the source, currently including:"), dest), extras) {
+				log.Printf("cancelled")
+				emitJSON(statusJSON{Status: "cancelled"})
+				emitResult("cancelled", "", 0, nil)
+				return
+			}
+		}
+	}
+
+	if err := preflightEnvironment(cfg); err != nil {
+		fatal("preflight", err)
+	}
+	if _, err := setupDataDir(dest, cfg); err != nil {
+		fatal("data dir", errcode.Wrap(errcode.SyncFailed, err))
+	}
+	stopTUI := func() {}
+	if *tuiFlag {
+		if stdinInteractive() {
+			stopTUI = startTUI(stop)
+		} else {
+			log.Printf("-tui needs a terminal; ignoring")
+		}
+	}
+	if *progressPipeFlag != "" {
+		if err := openProgressPipe(*progressPipeFlag); err != nil {
+			fatal("progress pipe", errcode.Wrap(errcode.ConfigInvalid, err))
+		}
+	}
+	opts := syncOptions{requireChecksum: *requireChecksum, stream: *streamFlag}
+	if _, err := syncOnce(ctx, dest, cfg, opts); err != nil {
+		recovered := false
+		if errors.Is(err, os.ErrPermission) {
+			var newDest string
+			if newDest, recovered = recoverPermissionDenied(ctx, dest, cfg, opts); recovered {
+				dest = newDest
+			}
+		}
+		if !recovered {
+			emitProgressDone(err)
+			flushTracing()
+			stopProfiles()
+			if errors.Is(err, context.Canceled) {
+				log.Printf("interrupted")
+				emitJSON(statusJSON{Status: "error", Error: "interrupted", Code: errcode.Interrupted, Exit: exitInterrupted})
+				emitResult("error", "", 0, err)
+				os.Exit(exitInterrupted)
+			}
+			notify(cfg, notifyTitle(cfg), "Install failed: "+err.Error())
+			fireWebhooks(cfg, "sync_failure", readVersion(dest), err.Error())
+			if perr := runPlugins("on_error", dest, cfg, err.Error()); perr != nil {
+				log.Printf("on-error plugin: %v", perr)
+			}
+			fatal("", err)
+		}
+	}
+	stopTUI()
+	emitProgressDone(nil)
+
+	if *watchFlag {
+		if err := watchAndSync(ctx, cfg.Source, dest, cfg, *settleFlag); err != nil {
+			fatal("watch", err)
+		}
+		emitResult("ok", "", 0, nil)
+		return
+	}
+
+	if *daemonFlag {
+		if err := daemonLoop(ctx, dest, cfg, *intervalFlag, opts, entryArgs, !*noLaunch, *statusAddr); err != nil {
+			fatal("daemon", err)
+		}
+		emitResult("ok", "", 0, nil)
+		return
+	}
+
+	prefetched := make(chan struct{})
+	if cfg.Prefetch && cfg.Feed != "" {
+		go func() {
+			defer close(prefetched)
+			prefetchNext(ctx, dest, cfg)
+		}()
+	} else {
+		close(prefetched)
+	}
+
+	if *noLaunch || (cfg.Entry == "" && len(cfg.Entries) == 0) {
+		<-prefetched
+		flushTracing()
+		stopProfiles()
+		emitJSON(statusJSON{Status: "ok"})
+		emitResult("ok", "", 0, nil)
+		return
+	}
+	if err := checkEntryArch(dest, cfg); err != nil {
+		fatal("preflight", err)
+	}
+	if err := checkPrereqs(dest, cfg); err != nil {
+		fatal("prerequisites", err)
+	}
+	if err := loadEnvFile(dest, cfg); err != nil {
+		fatal("env file", errcode.Wrap(errcode.ConfigInvalid, err))
+	}
+	if err := runPlugins("pre_launch", dest, cfg, ""); err != nil {
+		fatal("pre-launch", err)
+	}
+	if handleFirstRun(dest, cfg) {
+		launcher.AppendEnv("WRAPPER_FIRST_RUN=1")
+	}
+	recordLaunch(dest, cfg)
+	launchSpan := startSpan("launch")
+	var code int
+	if len(cfg.Entries) > 0 {
+		code, err = launchGroup(ctx, dest, cfg, entryArgs)
+	} else {
+		code, err = launchEntry(dest, cfg, entryArgs)
+	}
+	launchSpan.End()
+	flushTracing()
+	stopProfiles()
+	if err != nil {
+		fatal("launch", err)
+	}
+	<-prefetched
+	emitJSON(statusJSON{Status: "ok", Exit: code})
+	if len(cfg.Entries) > 0 || cfg.Wait {
+		emitResult("ok", "waited", code, nil)
+	} else {
+		emitResult("ok", "started", lastEntryPID(), nil)
+	}
+	os.Exit(code)
+}
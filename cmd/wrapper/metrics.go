@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Metrics are exported in the Prometheus text format on the status
+// endpoint. The handful of counters below does not justify pulling in
+// the client library, so the exposition is written by hand.
+
+type wrapperMetrics struct {
+	mu            sync.Mutex
+	syncTotal     uint64
+	syncFailures  uint64
+	syncDuration  float64 // seconds, most recent successful sync
+	bytesCopied   uint64
+	retries       uint64
+	childRestarts uint64
+	lastSuccess   time.Time
+}
+
+// metrics is process-global: the pipeline and the download layer
+// record into it regardless of which mode is driving them.
+var metrics = &wrapperMetrics{}
+
+func (m *wrapperMetrics) recordSync(d time.Duration, bytes uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.syncTotal++
+	m.syncDuration = d.Seconds()
+	m.bytesCopied += bytes
+	m.lastSuccess = time.Now()
+}
+
+func (m *wrapperMetrics) recordSyncFailure() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.syncTotal++
+	m.syncFailures++
+}
+
+func (m *wrapperMetrics) recordRetry() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries++
+}
+
+func (m *wrapperMetrics) recordChildRestart() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.childRestarts++
+}
+
+// write emits the exposition; one HELP/TYPE pair per series, matching
+// what promtool expects.
+func (m *wrapperMetrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	emit := func(name, help, kind string, value string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", name, help, name, kind, name, value)
+	}
+	emit("wrapper_sync_total", "Sync attempts since the process started.", "counter",
+		fmt.Sprintf("%d", m.syncTotal))
+	emit("wrapper_sync_failures_total", "Sync attempts that ended in an error.", "counter",
+		fmt.Sprintf("%d", m.syncFailures))
+	emit("wrapper_sync_duration_seconds", "Duration of the most recent successful sync.", "gauge",
+		fmt.Sprintf("%g", m.syncDuration))
+	emit("wrapper_sync_bytes_copied_total", "Payload bytes copied into dest.", "counter",
+		fmt.Sprintf("%d", m.bytesCopied))
+	emit("wrapper_download_retries_total", "Downloads that fell through to another mirror.", "counter",
+		fmt.Sprintf("%d", m.retries))
+	emit("wrapper_child_restarts_total", "Entry program restarts after updates.", "counter",
+		fmt.Sprintf("%d", m.childRestarts))
+	last := "0"
+	if !m.lastSuccess.IsZero() {
+		last = fmt.Sprintf("%d", m.lastSuccess.Unix())
+	}
+	emit("wrapper_last_success_timestamp_seconds", "Unix time of the last successful sync.", "gauge", last)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Migrations run exactly once when dest crosses a version edge — for
+// example renaming a settings file the new payload expects elsewhere.
+// Each is either a script shipped in the payload or a small built-in
+// action, and its result lands in the state file so a rerun (or a
+// failed run) is visible and never repeated.
+
+// runMigrations applies every configured migration matching the
+// from→to upgrade that has not been recorded yet.
+func runMigrations(dest string, cfg *Config, from, to string) {
+	if len(cfg.Migrations) == 0 {
+		return
+	}
+	st := loadState(dest)
+	if st.Migrations == nil {
+		st.Migrations = make(map[string]string)
+	}
+	changed := false
+	for i := range cfg.Migrations {
+		m := &cfg.Migrations[i]
+		id := migrationID(m, i)
+		if _, done := st.Migrations[id]; done {
+			continue
+		}
+		if !migrationMatches(m, from, to) {
+			continue
+		}
+		result := "ok"
+		if err := applyMigration(m, dest); err != nil {
+			log.Printf("migration %s: %v", id, err)
+			result = "error: " + err.Error()
+		} else {
+			log.Printf("migration %s applied", id)
+		}
+		st.Migrations[id] = result
+		changed = true
+	}
+	if changed {
+		if err := saveState(dest, st); err != nil {
+			log.Printf("record migrations: %v", err)
+		}
+	}
+}
+
+func migrationID(m *Migration, i int) string {
+	if m.ID != "" {
+		return m.ID
+	}
+	return fmt.Sprintf("%d:%s->%s", i, m.From, m.To)
+}
+
+// matches checks the upgrade edge. From/To are digest prefixes so the
+// config can use the same shortened form the logs print; empty matches
+// any version, and an empty From also matches a fresh install.
+func migrationMatches(m *Migration, from, to string) bool {
+	if m.From != "" && !strings.HasPrefix(from, m.From) {
+		return false
+	}
+	if m.To != "" && !strings.HasPrefix(to, m.To) {
+		return false
+	}
+	return true
+}
+
+// apply executes the migration inside dest.
+func applyMigration(m *Migration, dest string) error {
+	return applyAction(dest, m.Run, m.Action, m.Path)
+}
+
+// applyAction runs one script-or-builtin step; migrations and
+// first-run setup share it.
+func applyAction(dest string, run []string, action, relPath string) error {
+	switch {
+	case len(run) > 0:
+		rel, err := validateRelPath("migration run", run[0])
+		if err != nil {
+			return err
+		}
+		cmd := exec.Command(filepath.Join(dest, filepath.FromSlash(rel)), run[1:]...)
+		cmd.Dir = dest
+		cmd.Env = append(os.Environ(), "WRAPPER_DEST="+dest)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%v\n%s", err, out)
+		}
+		return nil
+	case action == "delete":
+		rel, err := validateRelPath("migration path", relPath)
+		if err != nil {
+			return err
+		}
+		return os.RemoveAll(filepath.Join(dest, filepath.FromSlash(rel)))
+	case action == "mkdir":
+		rel, err := validateRelPath("migration path", relPath)
+		if err != nil {
+			return err
+		}
+		return os.MkdirAll(filepath.Join(dest, filepath.FromSlash(rel)), 0o755)
+	default:
+		return fmt.Errorf("migration needs \"run\" or a known \"action\"")
+	}
+}
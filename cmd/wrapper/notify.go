@@ -0,0 +1,25 @@
+package main
+
+import "log"
+
+// notify shows a native desktop notification when the config opts in.
+// The wrapper usually runs without a visible console, so for sync
+// results this is often the only feedback the user gets. Failure to
+// display one is logged and otherwise ignored.
+func notify(cfg *Config, title, body string) {
+	if !cfg.Notifications {
+		return
+	}
+	if err := showNotification(title, body); err != nil {
+		log.Printf("notification: %v", err)
+	}
+}
+
+// notifyTitle is the notification headline: the install name when one
+// is configured.
+func notifyTitle(cfg *Config) string {
+	if cfg.Name != "" {
+		return cfg.Name
+	}
+	return "wrapper"
+}
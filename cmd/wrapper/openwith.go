@@ -0,0 +1,41 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// When Explorer invokes the wrapper through a registered association
+// the document path arrives as a positional argument, resolved
+// against whatever directory Explorer happened to use. The entry
+// program runs with dest as its working directory, so a relative
+// path would dangle; openWithArgs pins real paths down before the
+// launch. Spaces need no special care — arguments are passed as
+// discrete argv entries, never through a shell.
+
+// openWithArgs returns args with every argument that names an
+// existing file or directory converted to a cleaned absolute path;
+// everything else is passed through untouched.
+func openWithArgs(args []string) []string {
+	out := make([]string, len(args))
+	for i, a := range args {
+		out[i] = a
+		if a == "" || strings.HasPrefix(a, "-") {
+			continue
+		}
+		if _, err := os.Stat(a); err != nil {
+			continue
+		}
+		abs, err := filepath.Abs(a)
+		if err != nil {
+			continue
+		}
+		if abs != a {
+			log.Printf("forwarding opened path %s as %s", a, abs)
+		}
+		out[i] = abs
+	}
+	return out
+}
@@ -0,0 +1,109 @@
+//go:build !windows && !minimal
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// On Unix the PATH entry is a marked block appended to ~/.profile, so
+// uninstall can remove exactly what install added and nothing else.
+
+const pathBlockStart = "# >>> wrapper path (%s) >>>"
+const pathBlockEnd = "# <<< wrapper path (%s) <<<"
+
+func profilePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".profile"), nil
+}
+
+func pathMarkers(cfg *Config) (string, string) {
+	name := cfg.Name
+	if name == "" {
+		name = "default"
+	}
+	return fmt.Sprintf(pathBlockStart, name), fmt.Sprintf(pathBlockEnd, name)
+}
+
+// registerPath appends a guarded export block to ~/.profile.
+func registerPath(dest string, cfg *Config) error {
+	if cfg.AddToPath == "" {
+		return nil
+	}
+	profile, err := profilePath()
+	if err != nil {
+		return err
+	}
+	start, end := pathMarkers(cfg)
+	dir := filepath.Join(dest, filepath.FromSlash(cfg.AddToPath))
+	block := fmt.Sprintf("%s\nexport PATH=\"%s:$PATH\"\n%s\n", start, dir, end)
+
+	data, err := os.ReadFile(profile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	content := stripPathBlock(string(data), start, end)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if err := os.WriteFile(profile, []byte(content+block), 0o644); err != nil {
+		return err
+	}
+	blockName := cfg.Name
+	if blockName == "" {
+		blockName = "default"
+	}
+	recordChange("profile_block", blockName)
+	log.Printf("added %s to PATH via %s (takes effect at next login)", dir, profile)
+	return nil
+}
+
+// unregisterPath removes the guarded block.
+func unregisterPath(dest string, cfg *Config) error {
+	if cfg.AddToPath == "" {
+		return nil
+	}
+	profile, err := profilePath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(profile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	start, end := pathMarkers(cfg)
+	stripped := stripPathBlock(string(data), start, end)
+	if stripped == string(data) {
+		return nil
+	}
+	return os.WriteFile(profile, []byte(stripped), 0o644)
+}
+
+// stripPathBlock removes the lines between (and including) the
+// markers.
+func stripPathBlock(content, start, end string) string {
+	lines := strings.Split(content, "\n")
+	var kept []string
+	inBlock := false
+	for _, line := range lines {
+		switch {
+		case strings.TrimSpace(line) == start:
+			inBlock = true
+		case strings.TrimSpace(line) == end:
+			inBlock = false
+		case !inBlock:
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
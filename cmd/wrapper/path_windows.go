@@ -0,0 +1,116 @@
+//go:build windows && !minimal
+
+package main
+
+import (
+	"log"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// User PATH edits go to HKCU\Environment. New consoles only see the
+// change after a WM_SETTINGCHANGE broadcast, so one is sent after
+// every edit.
+
+func readUserPath() (registry.Key, string, uint32, error) {
+	k, _, err := registry.CreateKey(registry.CURRENT_USER, "Environment", registry.ALL_ACCESS)
+	if err != nil {
+		return 0, "", 0, err
+	}
+	val, typ, err := k.GetStringValue("Path")
+	if err == registry.ErrNotExist {
+		return k, "", registry.EXPAND_SZ, nil
+	}
+	if err != nil {
+		k.Close()
+		return 0, "", 0, err
+	}
+	return k, val, typ, nil
+}
+
+func writeUserPath(k registry.Key, val string, typ uint32) error {
+	var err error
+	if typ == registry.EXPAND_SZ {
+		err = k.SetExpandStringValue("Path", val)
+	} else {
+		err = k.SetStringValue("Path", val)
+	}
+	if err != nil {
+		return err
+	}
+	broadcastEnvChange()
+	return nil
+}
+
+// registerPath appends dest/<AddToPath> to the user PATH if absent.
+func registerPath(dest string, cfg *Config) error {
+	if cfg.AddToPath == "" {
+		return nil
+	}
+	dir := filepath.Join(dest, filepath.FromSlash(cfg.AddToPath))
+	k, val, typ, err := readUserPath()
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+	for _, p := range strings.Split(val, ";") {
+		if strings.EqualFold(strings.TrimSpace(p), dir) {
+			return nil
+		}
+	}
+	if val != "" && !strings.HasSuffix(val, ";") {
+		val += ";"
+	}
+	if err := writeUserPath(k, val+dir, typ); err != nil {
+		return err
+	}
+	recordChange("path_entry", dir)
+	log.Printf("added %s to the user PATH", dir)
+	return nil
+}
+
+// unregisterPath removes the entry registerPath added.
+func unregisterPath(dest string, cfg *Config) error {
+	if cfg.AddToPath == "" {
+		return nil
+	}
+	dir := filepath.Join(dest, filepath.FromSlash(cfg.AddToPath))
+	k, val, typ, err := readUserPath()
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+	var kept []string
+	removed := false
+	for _, p := range strings.Split(val, ";") {
+		if strings.EqualFold(strings.TrimSpace(p), dir) {
+			removed = true
+			continue
+		}
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	if !removed {
+		return nil
+	}
+	return writeUserPath(k, strings.Join(kept, ";"), typ)
+}
+
+// broadcastEnvChange tells running shells the environment changed.
+func broadcastEnvChange() {
+	const (
+		hwndBroadcast   = 0xFFFF
+		wmSettingChange = 0x001A
+		smtoAbortIfHung = 0x0002
+	)
+	user32 := syscall.NewLazyDLL("user32.dll")
+	proc := user32.NewProc("SendMessageTimeoutW")
+	env, _ := syscall.UTF16PtrFromString("Environment")
+	proc.Call(hwndBroadcast, wmSettingChange, 0,
+		uintptr(unsafe.Pointer(env)), smtoAbortIfHung, 5000, 0)
+}
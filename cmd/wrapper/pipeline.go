@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/errcode"
+)
+
+// isURL reports whether the configured source is a remote URL rather
+// than a local path.
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// sourceStableRetries bounds how often a sync is re-run because the
+// source changed underneath it.
+const sourceStableRetries = 3
+
+// syncOptions carries the per-invocation knobs that affect how the
+// payload is acquired; everything else comes from the Config.
+type syncOptions struct {
+	requireChecksum bool
+	stream          bool
+
+	// background marks syncs nobody is waiting on (daemon rounds),
+	// which a "defer" power policy may skip while on battery.
+	background bool
+}
+
+// syncOnce runs one pass of the acquire/verify/sync/integrate
+// pipeline: resolve the source (pre-fetched stage, streamed download,
+// mirror download or local path), extract archives, compare digests
+// and, when dest is stale, sync it and refresh the OS integrations.
+// It reports whether dest was actually modified.
+func syncOnce(ctx context.Context, dest string, cfg *Config, opts syncOptions) (bool, error) {
+	if applyPowerPolicy(cfg, opts.background) {
+		return false, nil
+	}
+	lock, err := acquireLock(dest)
+	if err != nil {
+		return false, errcode.Wrap(errcode.LockHeld, fmt.Errorf("lock: %w", err))
+	}
+	defer lock.release()
+
+	if err := runPlugins("pre_sync", dest, cfg, ""); err != nil {
+		return false, fmt.Errorf("pre-sync: %w", err)
+	}
+
+	source := cfg.Source
+	var digest, stagedDir string
+	if cfg.Prefetch && cfg.Feed != "" {
+		if s, ver, dir, err := adoptStaged(dest); err != nil {
+			log.Printf("staged versions: %v", err)
+		} else if s != "" {
+			log.Printf("switching to pre-fetched version %.12s…", ver)
+			source, digest, stagedDir = s, ver, dir
+		}
+	}
+	if digest == "" {
+		if isURL(source) && opts.stream && canStream(source, cfg) {
+			dlSpan := startSpan("download.stream")
+			source, err = streamExtractSource(ctx, source, dest, cfg, opts.requireChecksum)
+			dlSpan.End()
+			if err != nil {
+				return false, errcode.Wrap(errcode.DownloadFailed, fmt.Errorf("download: %w", err))
+			}
+		} else if isURL(source) {
+			dlSpan := startSpan("download")
+			source, err = downloadFromMirrors(ctx, append([]string{cfg.Source}, cfg.Mirrors...), dest)
+			dlSpan.End()
+			if err != nil {
+				return false, errcode.Wrap(errcode.DownloadFailed, fmt.Errorf("download: %w", err))
+			}
+			verifySpan := startSpan("verify")
+			if err := verifyDownload(ctx, source, cfg.Source, cfg, opts.requireChecksum); err != nil {
+				verifySpan.End()
+				return false, errcode.Wrap(errcode.VerifyFailed, fmt.Errorf("verify: %w", err))
+			}
+			err := verifySignature(ctx, source, cfg.Source, cfg)
+			verifySpan.End()
+			if err != nil {
+				return false, errcode.Wrap(errcode.SignatureInvalid, fmt.Errorf("verify: %w", err))
+			}
+		}
+	}
+	if isArchive(source) {
+		exSpan := startSpan("extract")
+		source, err = extractSource(source, cfg)
+		exSpan.End()
+		if err != nil {
+			return false, fmt.Errorf("extract: %w", err)
+		}
+	}
+	// snapshot fingerprints the source (and overlays) cheaply; taken
+	// before and after the copy pass it detects a source replaced
+	// mid-sync, e.g. 0install swapping the cache implementation.
+	snapshot := func() (string, error) {
+		d, err := sourceDigest(source, cfg.Excludes)
+		if err != nil {
+			return "", fmt.Errorf("hash source: %w", err)
+		}
+		for _, layer := range cfg.Overlays {
+			layerDigest, err := sourceDigest(layer, cfg.Excludes)
+			if err != nil {
+				return "", fmt.Errorf("hash overlay %s: %w", layer, err)
+			}
+			d = combineDigests(d, layerDigest)
+		}
+		return d, nil
+	}
+	digestFromSource := digest == ""
+	if digestFromSource {
+		digest, err = snapshot()
+		if err != nil {
+			return false, err
+		}
+	}
+
+	installedVersion := readVersion(dest)
+	if !cfg.Force && digest == installedVersion {
+		log.Printf("%s is up to date", dest)
+		return false, nil
+	}
+	if err := checkNameCollision(dest, cfg); err != nil {
+		return false, fmt.Errorf("install registry: %w", err)
+	}
+	if cfg.Scope == "machine" && !destWritable(dest) {
+		// Sync needs elevation but any launch must stay per-user, so
+		// the elevated instance only syncs.
+		log.Printf("machine-scope dest %s is not writable; requesting elevation for the sync", dest)
+		if err := relaunchElevated("-no-launch"); err != nil {
+			return false, fmt.Errorf("sync: %w", err)
+		}
+		return true, nil
+	}
+
+	start := time.Now()
+	var stats syncStats
+	before, err := snapshot()
+	if err != nil {
+		return false, err
+	}
+	for attempt := 1; ; attempt++ {
+		stats = syncStats{}
+		if len(cfg.Overlays) > 0 {
+			err = syncLayers(ctx, append([]string{source}, cfg.Overlays...), dest, cfg, &stats)
+		} else if cfg.SharedCache {
+			err = syncViaSharedCache(ctx, source, dest, digest, cfg, &stats)
+		} else {
+			err = syncDir(ctx, source, dest, cfg, &stats)
+		}
+		lastSync = stats
+		if err != nil {
+			metrics.recordSyncFailure()
+			err = errcode.Wrap(errcode.SyncFailed, fmt.Errorf("sync: %w", err))
+			reportTelemetry(cfg, time.Since(start), stats.Bytes, err)
+			return false, err
+		}
+		after, err := snapshot()
+		if err != nil {
+			return false, err
+		}
+		if after == before {
+			break
+		}
+		// Mixed-version dest: stamp it incomplete so no later run
+		// mistakes it for a finished install, then copy again from
+		// the now-current source.
+		if werr := writeVersion(dest, "incomplete"); werr != nil {
+			return false, fmt.Errorf("record version: %w", werr)
+		}
+		if attempt >= sourceStableRetries {
+			return false, errcode.Wrap(errcode.SyncFailed,
+				fmt.Errorf("source kept changing during sync (%d attempts); install left marked incomplete", attempt))
+		}
+		log.Printf("source changed during sync; re-running (attempt %d of %d)", attempt+1, sourceStableRetries)
+		before = after
+	}
+	if digestFromSource {
+		// Record what actually landed in dest, which a mid-sync source
+		// swap may have moved past the digest computed up front.
+		digest = before
+	}
+	if err := writeVersion(dest, digest); err != nil {
+		return false, fmt.Errorf("record version: %w", err)
+	}
+	runMigrations(dest, cfg, installedVersion, digest)
+	metrics.recordSync(time.Since(start), uint64(stats.Bytes))
+	reportTelemetry(cfg, time.Since(start), stats.Bytes, nil)
+	log.Printf("synced %d files (%d skipped, %d removed, %d bytes) in %s",
+		stats.Copied, stats.Skipped, stats.Deleted, stats.Bytes,
+		time.Since(start).Round(time.Millisecond))
+	if cfg.Dedup {
+		if links, saved, err := dedupDest(dest, cfg); err != nil {
+			log.Printf("dedup: %v", err)
+		} else if links > 0 {
+			log.Printf("dedup: collapsed %d duplicates, saved %d bytes", links, saved)
+		}
+	}
+	if cfg.CompressDest {
+		if err := compressDest(dest); errors.Is(err, errors.ErrUnsupported) {
+			log.Printf("NTFS compression is only available on Windows; ignoring")
+		} else if err != nil {
+			log.Printf("compress: %v", err)
+		}
+	}
+	notify(cfg, notifyTitle(cfg), fmt.Sprintf("Updated to version %.12s…", digest))
+	fireWebhooks(cfg, "sync_success", digest, "")
+	if stagedDir != "" {
+		os.RemoveAll(stagedDir)
+	}
+	loadChanges(dest)
+	if err := createShortcuts(dest, cfg); err != nil {
+		log.Printf("shortcuts: %v", err)
+	}
+	if err := registerFileAssociations(dest, cfg); err != nil {
+		log.Printf("file associations: %v", err)
+	}
+	if err := registerProtocols(dest, cfg); err != nil {
+		log.Printf("protocols: %v", err)
+	}
+	if err := writeAppsEntry(dest, cfg); err != nil {
+		log.Printf("apps entry: %v", err)
+	}
+	if err := provisionRegistryKeys(dest, cfg); err != nil {
+		log.Printf("registry keys: %v", err)
+	}
+	if err := registerAutostart(cfg); err != nil {
+		log.Printf("autostart: %v", err)
+	}
+	if err := createAppBundle(cfg); err != nil {
+		log.Printf("app bundle: %v", err)
+	}
+	if err := registerContextMenu(dest, cfg); err != nil {
+		log.Printf("context menu: %v", err)
+	}
+	if err := registerPath(dest, cfg); err != nil {
+		log.Printf("path registration: %v", err)
+	}
+	if err := provisionFirewallRules(dest, cfg); err != nil {
+		log.Printf("firewall rules: %v", err)
+	}
+	if err := saveChanges(dest); err != nil {
+		log.Printf("change manifest: %v", err)
+	}
+	registerInstall(dest, cfg, digest)
+	if err := runPlugins("post_sync", dest, cfg, ""); err != nil {
+		log.Printf("post-sync plugin: %v", err)
+	}
+	gcOpportunistic(dest, cfg)
+	return true, nil
+}
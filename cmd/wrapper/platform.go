@@ -0,0 +1,12 @@
+package main
+
+import "github.com/doudou0720/Aiwb_0Installer_Feed/internal/platform"
+
+// The per-OS integrations live in internal/platform behind
+// interfaces; this file keeps the names the rest of the command
+// already uses.
+var relaunchElevated = platform.Elevation.RelaunchElevated
+
+func showNotification(title, body string) error {
+	return platform.Notifications.Show(title, body)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/launcher"
+)
+
+// Plugins let an organization bolt custom steps — license activation,
+// inventory reporting, AV exclusion requests — onto the wrapper
+// without forking it. A plugin is any executable: the wrapper runs it
+// at each configured hook point with one JSON request on stdin and
+// reads an optional JSON reply from stdout.
+//
+// Request:
+//
+//	{"hook":"pre_sync","name":"Aiwb","dest":"...","version":"...","error":"..."}
+//
+// Reply (all fields optional):
+//
+//	{"message":"shown in the log","env":{"KEY":"value for the entry program"}}
+//
+// A non-zero exit from a pre_sync or pre_launch plugin aborts that
+// stage; post_sync and on_error plugins are best-effort and only
+// logged.
+
+// pluginTimeout bounds each plugin invocation.
+const pluginTimeout = 30 * time.Second
+
+type pluginRequest struct {
+	Hook    string `json:"hook"`
+	Name    string `json:"name"`
+	Dest    string `json:"dest"`
+	Version string `json:"version"`
+	Error   string `json:"error,omitempty"`
+}
+
+type pluginReply struct {
+	Message string            `json:"message,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+// runPlugins invokes every configured plugin that subscribes to hook.
+// The returned error is the first plugin failure; callers decide
+// whether that aborts the stage or is merely logged.
+func runPlugins(hook, dest string, cfg *Config, errMsg string) error {
+	for i := range cfg.Plugins {
+		p := &cfg.Plugins[i]
+		if !pluginWants(p, hook) {
+			continue
+		}
+		if err := runPlugin(p, hook, dest, cfg, errMsg); err != nil {
+			return fmt.Errorf("plugin %s: %w", p.Path, err)
+		}
+	}
+	return nil
+}
+
+func pluginWants(p *Plugin, hook string) bool {
+	if len(p.Hooks) == 0 {
+		return true
+	}
+	for _, h := range p.Hooks {
+		if h == hook {
+			return true
+		}
+	}
+	return false
+}
+
+func runPlugin(p *Plugin, hook, dest string, cfg *Config, errMsg string) error {
+	req, err := json.Marshal(pluginRequest{
+		Hook:    hook,
+		Name:    cfg.Name,
+		Dest:    dest,
+		Version: readVersion(dest),
+		Error:   errMsg,
+	})
+	if err != nil {
+		return err
+	}
+	path := p.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dest, filepath.FromSlash(path))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Dir = dest
+	cmd.Stdin = bytes.NewReader(append(req, '\n'))
+	out, err := cmd.Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(ee.Stderr)))
+		}
+		return err
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil
+	}
+	var reply pluginReply
+	if err := json.Unmarshal(bytes.TrimSpace(out), &reply); err != nil {
+		return fmt.Errorf("bad reply: %w", err)
+	}
+	if reply.Message != "" {
+		log.Printf("plugin %s: %s", p.Path, reply.Message)
+	}
+	for k, v := range reply.Env {
+		launcher.AppendEnv(k + "=" + v)
+	}
+	return nil
+}
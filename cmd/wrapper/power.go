@@ -0,0 +1,42 @@
+package main
+
+import (
+	"log"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/syncer"
+)
+
+// Power-aware syncing: on a laptop running from its battery a full
+// parallel copy is an unfriendly neighbour. The power_policy config
+// key picks the behaviour — "throttle" drops the copy pool to one
+// worker until AC power returns, "defer" additionally skips
+// background (daemon) syncs entirely while on battery. Forced syncs
+// always run.
+const (
+	powerPolicyThrottle = "throttle"
+	powerPolicyDefer    = "defer"
+)
+
+// applyPowerPolicy configures the engine for the current power state
+// and reports whether this sync should be skipped outright.
+func applyPowerPolicy(cfg *Config, background bool) bool {
+	switch cfg.PowerPolicy {
+	case "", "none":
+		return false
+	case powerPolicyThrottle, powerPolicyDefer:
+	default:
+		log.Printf("power_policy: unknown policy %q; ignoring", cfg.PowerPolicy)
+		return false
+	}
+	if !onBattery() {
+		syncer.SetWorkerLimit(0)
+		return false
+	}
+	if cfg.PowerPolicy == powerPolicyDefer && background && !cfg.Force {
+		log.Printf("on battery; deferring background sync until AC power returns")
+		return true
+	}
+	log.Printf("on battery; throttling sync to one copy worker")
+	syncer.SetWorkerLimit(1)
+	return false
+}
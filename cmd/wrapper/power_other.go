@@ -0,0 +1,42 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// onBattery reports whether the machine runs from its battery;
+// machines with no battery at all (or an unreadable power state)
+// count as AC so desktops never throttle. Linux exposes AC adapters
+// as "Mains" power supplies in sysfs; macOS is asked via pmset.
+func onBattery() bool {
+	if runtime.GOOS == "darwin" {
+		out, err := exec.Command("pmset", "-g", "batt").Output()
+		if err != nil {
+			return false
+		}
+		return strings.Contains(string(out), "Battery Power")
+	}
+	supplies, err := filepath.Glob("/sys/class/power_supply/*/type")
+	if err != nil {
+		return false
+	}
+	sawMains := false
+	for _, typePath := range supplies {
+		t, err := os.ReadFile(typePath)
+		if err != nil || strings.TrimSpace(string(t)) != "Mains" {
+			continue
+		}
+		sawMains = true
+		online, err := os.ReadFile(filepath.Join(filepath.Dir(typePath), "online"))
+		if err == nil && strings.TrimSpace(string(online)) == "1" {
+			return false
+		}
+	}
+	return sawMains
+}
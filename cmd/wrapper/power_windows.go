@@ -0,0 +1,34 @@
+//go:build windows
+
+package main
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	modkernel32              = windows.NewLazySystemDLL("kernel32.dll")
+	procGetSystemPowerStatus = modkernel32.NewProc("GetSystemPowerStatus")
+)
+
+type systemPowerStatus struct {
+	ACLineStatus        byte
+	BatteryFlag         byte
+	BatteryLifePercent  byte
+	SystemStatusFlag    byte
+	BatteryLifeTime     uint32
+	BatteryFullLifeTime uint32
+}
+
+// onBattery reports whether the machine runs from its battery;
+// unknown power states count as AC so desktops never throttle.
+func onBattery() bool {
+	var st systemPowerStatus
+	r, _, _ := procGetSystemPowerStatus.Call(uintptr(unsafe.Pointer(&st)))
+	if r == 0 {
+		return false
+	}
+	return st.ACLineStatus == 0
+}
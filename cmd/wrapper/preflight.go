@@ -0,0 +1,138 @@
+package main
+
+import (
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"fmt"
+	"log"
+	"path/filepath"
+	"runtime"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/errcode"
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+)
+
+// Preflight checks catch environment problems up front — an OS below
+// the payload's floor, missing admin rights, an x64 binary on an ARM
+// machine that cannot emulate it — and report them plainly instead of
+// letting sync or launch die with a cryptic error.
+
+// preflightEnvironment verifies the config's OS-version floor and
+// admin requirement before any syncing starts.
+func preflightEnvironment(cfg *config.Config) error {
+	if cfg.MinOSVersion != "" {
+		v, err := osVersion()
+		if err != nil {
+			log.Printf("preflight: cannot determine OS version: %v", err)
+		} else if !versionAtLeast(v, cfg.MinOSVersion) {
+			return errcode.Wrap(errcode.LaunchFailed,
+				fmt.Errorf("this application needs %s %s or newer; this machine runs %s", runtime.GOOS, cfg.MinOSVersion, v))
+		}
+	}
+	if cfg.RequireAdmin && !isAdmin() {
+		return errcode.Wrap(errcode.LaunchFailed,
+			fmt.Errorf("this application must be run with administrative rights; re-run from an elevated prompt"))
+	}
+	return nil
+}
+
+// checkEntryArch rejects an entry binary the machine cannot execute,
+// after sync put it in place but before the OS produces its own far
+// less helpful error. Non-executable formats (scripts) are skipped.
+func checkEntryArch(dest string, cfg *config.Config) error {
+	if cfg.Entry == "" {
+		return nil
+	}
+	path := filepath.Join(dest, filepath.FromSlash(cfg.Entry))
+	arch, ok := binaryArch(path)
+	if !ok || arch == runtime.GOARCH {
+		return nil
+	}
+	if emulated(arch) {
+		log.Printf("preflight: %s is %s; running under %s emulation", cfg.Entry, arch, runtime.GOARCH)
+		return nil
+	}
+	return errcode.Wrap(errcode.LaunchFailed,
+		fmt.Errorf("%s is built for %s but this machine is %s/%s", cfg.Entry, arch, runtime.GOOS, runtime.GOARCH))
+}
+
+// binaryArch reads the executable header and maps its machine type
+// to a GOARCH string; ok is false for unreadable or non-native files.
+func binaryArch(path string) (string, bool) {
+	if f, err := pe.Open(path); err == nil {
+		defer f.Close()
+		switch f.Machine {
+		case pe.IMAGE_FILE_MACHINE_AMD64:
+			return "amd64", true
+		case pe.IMAGE_FILE_MACHINE_I386:
+			return "386", true
+		case pe.IMAGE_FILE_MACHINE_ARM64:
+			return "arm64", true
+		}
+		return "", false
+	}
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+		switch f.Machine {
+		case elf.EM_X86_64:
+			return "amd64", true
+		case elf.EM_386:
+			return "386", true
+		case elf.EM_AARCH64:
+			return "arm64", true
+		case elf.EM_ARM:
+			return "arm", true
+		}
+		return "", false
+	}
+	// A universal binary runs natively if any slice matches.
+	if f, err := macho.OpenFat(path); err == nil {
+		defer f.Close()
+		for _, a := range f.Arches {
+			if machoArch(a.Cpu) == runtime.GOARCH {
+				return runtime.GOARCH, true
+			}
+		}
+		if len(f.Arches) > 0 {
+			return machoArch(f.Arches[0].Cpu), true
+		}
+		return "", false
+	}
+	if f, err := macho.Open(path); err == nil {
+		defer f.Close()
+		if a := machoArch(f.Cpu); a != "" {
+			return a, true
+		}
+	}
+	return "", false
+}
+
+func machoArch(cpu macho.Cpu) string {
+	switch cpu {
+	case macho.CpuAmd64:
+		return "amd64"
+	case macho.CpuArm64:
+		return "arm64"
+	}
+	return ""
+}
+
+// emulated reports whether this machine can transparently run a
+// binary of the given foreign architecture: Windows emulates x86 (and
+// from Windows 11 also x64) on ARM64, and Apple silicon runs x64
+// through Rosetta. Linux has no such layer.
+func emulated(arch string) bool {
+	switch runtime.GOOS {
+	case "windows":
+		if runtime.GOARCH == "amd64" && arch == "386" {
+			return true
+		}
+		if runtime.GOARCH == "arm64" && (arch == "386" || arch == "amd64") {
+			return true
+		}
+	case "darwin":
+		return runtime.GOARCH == "arm64" && arch == "amd64"
+	}
+	return false
+}
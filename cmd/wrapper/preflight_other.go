@@ -0,0 +1,34 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// osVersion reports the product version on macOS and the kernel
+// release elsewhere (e.g. "14.2" vs "6.1.0-18-amd64").
+func osVersion() (string, error) {
+	if runtime.GOOS == "darwin" {
+		out, err := exec.Command("sw_vers", "-productVersion").Output()
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(out)), nil
+	}
+	var u unix.Utsname
+	if err := unix.Uname(&u); err != nil {
+		return "", err
+	}
+	return unix.ByteSliceToString(u.Release[:]), nil
+}
+
+// isAdmin reports whether the process runs as root.
+func isAdmin() bool {
+	return os.Geteuid() == 0
+}
@@ -0,0 +1,21 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// osVersion reports the kernel version as major.minor.build;
+// RtlGetVersion is unaffected by manifest-based version lying.
+func osVersion() (string, error) {
+	info := windows.RtlGetVersion()
+	return fmt.Sprintf("%d.%d.%d", info.MajorVersion, info.MinorVersion, info.BuildNumber), nil
+}
+
+// isAdmin reports whether the process token is elevated.
+func isAdmin() bool {
+	return windows.GetCurrentProcessToken().IsElevated()
+}
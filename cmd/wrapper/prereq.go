@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/errcode"
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+)
+
+// Prerequisite checks run between sync and launch: the config
+// declares runtimes the payload needs (VC++ redistributable, .NET,
+// WebView2, glibc) and the wrapper verifies them up front, so the
+// user sees "install the .NET runtime" instead of a cryptic loader
+// error from the entry program.
+
+// prereqHints maps a prerequisite type to the remedial action shown
+// when the check fails and no bundled installer is configured.
+var prereqHints = map[string]string{
+	"vcredist": "install the Microsoft Visual C++ Redistributable (x64)",
+	"dotnet":   "install the .NET runtime from https://dotnet.microsoft.com/download",
+	"webview2": "install the WebView2 runtime from https://developer.microsoft.com/microsoft-edge/webview2/",
+	"glibc":    "upgrade the system C library",
+}
+
+// checkPrereqs verifies every configured prerequisite, running the
+// bundled installer once when one is configured and the check fails.
+// Types that do not apply to this OS are skipped, so one config can
+// serve several platforms.
+func checkPrereqs(dest string, cfg *config.Config) error {
+	for _, p := range cfg.Prereqs {
+		if _, ok := prereqHints[p.Type]; !ok {
+			return errcode.Wrap(errcode.ConfigInvalid,
+				fmt.Errorf("unknown prerequisite type %q", p.Type))
+		}
+		if err := checkPrereq(dest, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkPrereq(dest string, p config.PrereqConfig) error {
+	v, applicable, err := prereqVersion(p.Type)
+	if !applicable {
+		return nil
+	}
+	if err == nil && versionAtLeast(v, p.MinVersion) {
+		return nil
+	}
+	if p.Installer != "" {
+		installer := filepath.Join(dest, filepath.FromSlash(p.Installer))
+		log.Printf("prerequisite %s not satisfied; running %s", p.Type, installer)
+		cmd := exec.Command(installer)
+		cmd.Dir = dest
+		if ierr := cmd.Run(); ierr != nil {
+			return errcode.Wrap(errcode.LaunchFailed,
+				fmt.Errorf("prerequisite installer for %s: %w", p.Type, ierr))
+		}
+		if v, _, err = prereqVersion(p.Type); err == nil && versionAtLeast(v, p.MinVersion) {
+			return nil
+		}
+	}
+	have := "not found"
+	if err == nil && v != "" {
+		have = "found " + v
+	}
+	return errcode.Wrap(errcode.LaunchFailed,
+		fmt.Errorf("prerequisite %s >= %s not satisfied (%s); %s", p.Type, p.MinVersion, have, prereqHints[p.Type]))
+}
+
+// dotnetVersion asks the dotnet host for its installed runtimes and
+// returns the highest Microsoft.NETCore.App version.
+func dotnetVersion() (string, error) {
+	out, err := exec.Command("dotnet", "--list-runtimes").Output()
+	if err != nil {
+		return "", fmt.Errorf("dotnet host not found: %w", err)
+	}
+	best := ""
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "Microsoft.NETCore.App" {
+			if best == "" || versionAtLeast(fields[1], best) {
+				best = fields[1]
+			}
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("no .NET runtime installed")
+	}
+	return best, nil
+}
+
+// versionAtLeast compares dotted numeric versions, tolerating a
+// leading "v" and non-numeric suffixes in each field; missing fields
+// count as zero.
+func versionAtLeast(have, want string) bool {
+	h := strings.Split(strings.TrimPrefix(have, "v"), ".")
+	w := strings.Split(strings.TrimPrefix(want, "v"), ".")
+	for i := 0; i < len(h) || i < len(w); i++ {
+		hv, wv := 0, 0
+		if i < len(h) {
+			hv = leadingInt(h[i])
+		}
+		if i < len(w) {
+			wv = leadingInt(w[i])
+		}
+		if hv != wv {
+			return hv > wv
+		}
+	}
+	return true
+}
+
+func leadingInt(s string) int {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	n, _ := strconv.Atoi(s[:i])
+	return n
+}
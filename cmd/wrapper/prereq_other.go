@@ -0,0 +1,43 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// prereqVersion reports the installed version of a prerequisite, or
+// applicable=false when the type has no meaning here: the VC++ and
+// WebView2 runtimes are Windows-only, and glibc only exists on Linux.
+func prereqVersion(typ string) (string, bool, error) {
+	switch typ {
+	case "vcredist", "webview2":
+		return "", false, nil
+	case "dotnet":
+		v, err := dotnetVersion()
+		return v, true, err
+	case "glibc":
+		if runtime.GOOS != "linux" {
+			return "", false, nil
+		}
+		v, err := glibcVersion()
+		return v, true, err
+	}
+	return "", false, fmt.Errorf("unknown prerequisite type %q", typ)
+}
+
+// glibcVersion asks getconf, whose answer looks like "glibc 2.35".
+func glibcVersion() (string, error) {
+	out, err := exec.Command("getconf", "GNU_LIBC_VERSION").Output()
+	if err != nil {
+		return "", fmt.Errorf("getconf GNU_LIBC_VERSION: %w", err)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) < 2 {
+		return "", fmt.Errorf("unexpected getconf output %q", strings.TrimSpace(string(out)))
+	}
+	return fields[len(fields)-1], nil
+}
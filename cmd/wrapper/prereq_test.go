@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestVersionAtLeast(t *testing.T) {
+	cases := []struct {
+		have, want string
+		ok         bool
+	}{
+		{"2.35", "2.31", true},
+		{"2.31", "2.35", false},
+		{"8.0.1", "8.0", true},
+		{"8.0", "8.0.1", false},
+		{"v14.38.33135.00", "14.38", true},
+		{"6.0.25", "8.0", false},
+		{"8.0", "8.0", true},
+	}
+	for _, c := range cases {
+		if got := versionAtLeast(c.have, c.want); got != c.ok {
+			t.Errorf("versionAtLeast(%q, %q) = %v, want %v", c.have, c.want, got, c.ok)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	vcRuntimeKey = `SOFTWARE\Microsoft\VisualStudio\14.0\VC\Runtimes\x64`
+	webview2Key  = `SOFTWARE\WOW6432Node\Microsoft\EdgeUpdate\Clients\{F3017226-FE2A-4295-8BDF-00C3A9A7E4C5}`
+)
+
+// prereqVersion reports the installed version of a prerequisite, or
+// applicable=false when the type has no meaning on Windows.
+func prereqVersion(typ string) (string, bool, error) {
+	switch typ {
+	case "vcredist":
+		v, err := regString(registry.LOCAL_MACHINE, vcRuntimeKey, "Version")
+		return v, true, err
+	case "webview2":
+		// Per-machine install; "pv" holds the runtime version.
+		v, err := regString(registry.LOCAL_MACHINE, webview2Key, "pv")
+		return v, true, err
+	case "dotnet":
+		v, err := dotnetVersion()
+		return v, true, err
+	case "glibc":
+		return "", false, nil
+	}
+	return "", false, fmt.Errorf("unknown prerequisite type %q", typ)
+}
+
+func regString(root registry.Key, path, name string) (string, error) {
+	k, err := registry.OpenKey(root, path, registry.QUERY_VALUE)
+	if err != nil {
+		return "", err
+	}
+	defer k.Close()
+	v, _, err := k.GetStringValue(name)
+	return v, err
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// Profiling support for performance work on the copy pipeline: the
+// -pprof-cpu and -pprof-mem flags write standard pprof files that
+// "go tool pprof" reads, so a user with a slow sync can capture a
+// profile on their own hardware and attach it to a report.
+
+var profileCPUFile *os.File
+var profileMemPath string
+
+// initProfiles starts CPU profiling and/or arranges a heap profile at
+// exit, depending on which paths are non-empty.
+func initProfiles(cpuPath, memPath string) error {
+	if cpuPath != "" {
+		f, err := os.Create(cpuPath)
+		if err != nil {
+			return fmt.Errorf("create %s: %w", cpuPath, err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return err
+		}
+		profileCPUFile = f
+	}
+	profileMemPath = memPath
+	return nil
+}
+
+// stopProfiles flushes any active profiles. It sits next to
+// flushTracing at every exit path and is safe to call when profiling
+// is off or already stopped.
+func stopProfiles() {
+	if profileCPUFile != nil {
+		pprof.StopCPUProfile()
+		profileCPUFile.Close()
+		profileCPUFile = nil
+	}
+	if profileMemPath != "" {
+		f, err := os.Create(profileMemPath)
+		if err == nil {
+			runtime.GC()
+			pprof.WriteHeapProfile(f)
+			f.Close()
+		}
+		profileMemPath = ""
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/syncer"
+
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/errcode"
+)
+
+// The progress pipe is the machine-readable twin of the TUI: a parent
+// installer or a GUI in another language passes -progress-pipe (a
+// named pipe it created, or any writable path) and receives one JSON
+// object per line while the wrapper works, ending with a "done" event.
+// Events mirror the syncer.Events callbacks one to one, so the
+// protocol grows only when the engine does.
+
+// progressEvent is one line on the pipe.
+type progressEvent struct {
+	Event string `json:"event"` // "phase", "file_start", "file_done", "error", "done"
+	Phase string `json:"phase,omitempty"`
+	Path  string `json:"path,omitempty"`
+	Size  int64  `json:"size,omitempty"`
+	Error string `json:"error,omitempty"`
+	Code  string `json:"code,omitempty"` // errcode, on "done" only
+}
+
+// pipeSink writes events to the pipe and forwards them to whatever
+// sink was installed before it, so logging (or the TUI) keeps working.
+type pipeSink struct {
+	mu   sync.Mutex
+	enc  *json.Encoder
+	f    *os.File
+	prev syncer.Events
+}
+
+func (p *pipeSink) emit(ev progressEvent) {
+	p.mu.Lock()
+	p.enc.Encode(ev)
+	p.mu.Unlock()
+}
+
+func (p *pipeSink) OnPhaseChange(phase string) {
+	p.emit(progressEvent{Event: "phase", Phase: phase})
+	p.prev.OnPhaseChange(phase)
+}
+
+func (p *pipeSink) OnFileStart(rel string, size int64) {
+	p.emit(progressEvent{Event: "file_start", Path: rel, Size: size})
+	p.prev.OnFileStart(rel, size)
+}
+
+func (p *pipeSink) OnFileDone(rel string, size int64, err error) {
+	ev := progressEvent{Event: "file_done", Path: rel, Size: size}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+	p.emit(ev)
+	p.prev.OnFileDone(rel, size, err)
+}
+
+func (p *pipeSink) OnError(err error) {
+	p.emit(progressEvent{Event: "error", Error: err.Error()})
+	p.prev.OnError(err)
+}
+
+// progressPipe is nil unless -progress-pipe was given.
+var progressPipe *pipeSink
+
+// openProgressPipe connects the sink. Opening a FIFO blocks until the
+// parent opens its end, which is the handshake the protocol wants.
+func openProgressPipe(path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	progressPipe = &pipeSink{enc: json.NewEncoder(f), f: f}
+	progressPipe.prev = syncer.SetEvents(progressPipe)
+	return nil
+}
+
+// emitProgressDone terminates the stream with the overall outcome and
+// closes the pipe; it is a no-op when no pipe was requested.
+func emitProgressDone(err error) {
+	if progressPipe == nil {
+		return
+	}
+	ev := progressEvent{Event: "done"}
+	if err != nil {
+		ev.Error = err.Error()
+		ev.Code = string(errcode.Of(err))
+	}
+	progressPipe.emit(ev)
+	progressPipe.f.Close()
+	syncer.SetEvents(progressPipe.prev)
+	progressPipe = nil
+}
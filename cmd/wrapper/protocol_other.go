@@ -0,0 +1,10 @@
+//go:build !windows || minimal
+
+package main
+
+// On Linux, scheme handlers ride along in the shared .desktop entry
+// (x-scheme-handler mime types) written by the file-association code;
+// elsewhere they are unsupported.
+
+func registerProtocols(dest string, cfg *Config) error { return nil }
+func unregisterProtocols(cfg *Config) error            { return nil }
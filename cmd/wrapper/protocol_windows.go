@@ -0,0 +1,52 @@
+//go:build windows && !minimal
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+// URL protocol handlers are the same HKCU\Software\Classes shape as
+// file associations, plus the empty "URL Protocol" marker value that
+// tells the shell the key is a scheme.
+
+func registerProtocols(dest string, cfg *Config) error {
+	if len(cfg.Protocols) == 0 {
+		return nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	for _, scheme := range cfg.Protocols {
+		if err := validateScheme(scheme); err != nil {
+			return err
+		}
+		if err := setClassValue(scheme, "", "URL:"+scheme+" protocol"); err != nil {
+			return fmt.Errorf("register %s://: %w", scheme, err)
+		}
+		if err := setClassValue(scheme, "URL Protocol", ""); err != nil {
+			return fmt.Errorf("register %s://: %w", scheme, err)
+		}
+		cmd := fmt.Sprintf(`"%s" "%%1"`, exe)
+		if err := setClassValue(scheme+`\shell\open\command`, "", cmd); err != nil {
+			return fmt.Errorf("register %s://: %w", scheme, err)
+		}
+		recordChange("regkey", `Software\Classes\`+scheme)
+		log.Printf("registered protocol %s://", scheme)
+	}
+	return nil
+}
+
+func unregisterProtocols(cfg *Config) error {
+	for _, scheme := range cfg.Protocols {
+		deleteClassKey(scheme + `\shell\open\command`)
+		deleteClassKey(scheme + `\shell\open`)
+		deleteClassKey(scheme + `\shell`)
+		deleteClassKey(scheme)
+		log.Printf("removed protocol %s://", scheme)
+	}
+	return nil
+}
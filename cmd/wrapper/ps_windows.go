@@ -0,0 +1,10 @@
+//go:build windows
+
+package main
+
+import "strings"
+
+// psQuote single-quotes a string for PowerShell.
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
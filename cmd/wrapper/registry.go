@@ -0,0 +1,159 @@
+package main
+
+import (
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/version"
+
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// The install registry is one JSON file in the parent directory of the
+// dests (the per-user or machine install root), tracking every named
+// install: what is where, which version, how big, when it was last
+// launched. list/uninstall/GC tooling builds on it, and it catches two
+// different payloads claiming the same -name before they overwrite
+// each other.
+
+const registryFileName = ".wrapper-installs.json"
+
+// wrapperVersion mirrors pkg/version.Version, which release builds
+// stamp via -ldflags.
+var wrapperVersion = version.Version
+
+type installRecord struct {
+	Name           string    `json:"name"`
+	Dest           string    `json:"dest"`
+	Source         string    `json:"source,omitempty"`
+	Version        string    `json:"version"`
+	SizeBytes      int64     `json:"size_bytes"`
+	InstalledAt    time.Time `json:"installed_at"`
+	LastLaunch     time.Time `json:"last_launch,omitempty"`
+	WrapperVersion string    `json:"wrapper_version"`
+}
+
+type installRegistry struct {
+	Installs map[string]*installRecord `json:"installs"`
+}
+
+func registryPath(dest string) string {
+	return filepath.Join(filepath.Dir(dest), registryFileName)
+}
+
+// loadRegistry returns an empty registry when none exists yet.
+func loadRegistry(dest string) *installRegistry {
+	reg := &installRegistry{Installs: make(map[string]*installRecord)}
+	data, err := os.ReadFile(registryPath(dest))
+	if err != nil {
+		return reg
+	}
+	if err := json.Unmarshal(data, reg); err != nil {
+		log.Printf("install registry: %v (starting fresh)", err)
+		reg.Installs = make(map[string]*installRecord)
+	}
+	if reg.Installs == nil {
+		reg.Installs = make(map[string]*installRecord)
+	}
+	return reg
+}
+
+func (reg *installRegistry) save(dest string) error {
+	data, err := json.MarshalIndent(reg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(registryPath(dest), append(data, '\n'), 0o644)
+}
+
+// checkNameCollision rejects an install whose -name is already
+// registered for a different dest; two payloads sharing one name would
+// otherwise silently overwrite each other.
+func checkNameCollision(dest string, cfg *Config) error {
+	if cfg.Name == "" {
+		return nil
+	}
+	rec, ok := loadRegistry(dest).Installs[cfg.Name]
+	if ok && rec.Dest != dest {
+		return fmt.Errorf("name %q is already registered for %s", cfg.Name, rec.Dest)
+	}
+	return nil
+}
+
+// registerInstall records or refreshes this install after a sync.
+func registerInstall(dest string, cfg *Config, digest string) {
+	if cfg.Name == "" {
+		return
+	}
+	reg := loadRegistry(dest)
+	rec, ok := reg.Installs[cfg.Name]
+	if !ok {
+		rec = &installRecord{Name: cfg.Name, InstalledAt: time.Now()}
+		reg.Installs[cfg.Name] = rec
+	}
+	rec.Dest = dest
+	rec.Source = cfg.Source
+	rec.Version = digest
+	rec.SizeBytes = destSize(dest)
+	rec.WrapperVersion = wrapperVersion
+	if err := reg.save(dest); err != nil {
+		log.Printf("install registry: %v", err)
+	}
+}
+
+// recordLaunch stamps the last-launch time.
+func recordLaunch(dest string, cfg *Config) {
+	if cfg.Name == "" {
+		return
+	}
+	reg := loadRegistry(dest)
+	rec, ok := reg.Installs[cfg.Name]
+	if !ok {
+		return
+	}
+	rec.LastLaunch = time.Now()
+	if err := reg.save(dest); err != nil {
+		log.Printf("install registry: %v", err)
+	}
+}
+
+// unregisterInstall drops the record at uninstall.
+func unregisterInstall(dest string, cfg *Config) {
+	if cfg.Name == "" {
+		return
+	}
+	reg := loadRegistry(dest)
+	if _, ok := reg.Installs[cfg.Name]; !ok {
+		return
+	}
+	delete(reg.Installs, cfg.Name)
+	if err := reg.save(dest); err != nil {
+		log.Printf("install registry: %v", err)
+	}
+}
+
+// destSize totals the payload bytes under dest, skipping the
+// wrapper's own bookkeeping and download cache.
+func destSize(dest string) int64 {
+	var total int64
+	filepath.Walk(dest, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, rerr := filepath.Rel(dest, path)
+		if rerr != nil {
+			return nil
+		}
+		if rel == downloadsDirName || strings.HasPrefix(rel, stagingDirPrefix) {
+			return filepath.SkipDir
+		}
+		if info.Mode().IsRegular() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/platform"
+)
+
+// expandRegTemplate substitutes the supported placeholders in a
+// configured registry value or path.
+func expandRegTemplate(s, dest string, cfg *Config) string {
+	r := strings.NewReplacer(
+		"{dest}", dest,
+		"{name}", cfg.Name,
+		"{entry}", cfg.Entry,
+		"{version}", readVersion(dest),
+	)
+	return r.Replace(s)
+}
+
+// regKeySpecs expands the configured registry keys into the
+// platform-neutral specs the integration layer consumes.
+func regKeySpecs(dest string, cfg *Config) []platform.KeySpec {
+	keys := make([]platform.KeySpec, 0, len(cfg.RegistryKeys))
+	for _, rk := range cfg.RegistryKeys {
+		ks := platform.KeySpec{
+			Path:   expandRegTemplate(rk.Path, dest, cfg),
+			Values: make(map[string]any, len(rk.Values)),
+		}
+		for name, val := range rk.Values {
+			if s, ok := val.(string); ok {
+				ks.Values[name] = expandRegTemplate(s, dest, cfg)
+			} else {
+				ks.Values[name] = val
+			}
+		}
+		keys = append(keys, ks)
+	}
+	return keys
+}
+
+// provisionRegistryKeys creates the configured keys and values.
+func provisionRegistryKeys(dest string, cfg *Config) error {
+	if len(cfg.RegistryKeys) == 0 {
+		return nil
+	}
+	created, err := platform.Registry.Provision(regKeySpecs(dest, cfg))
+	for _, path := range created {
+		recordChange("regkey", path)
+		log.Printf("provisioned HKCU\\%s", path)
+	}
+	if errors.Is(err, errors.ErrUnsupported) {
+		log.Printf("registry_keys are only applied on Windows; ignoring")
+		return nil
+	}
+	return err
+}
+
+// removeRegistryKeys deletes the provisioned keys at uninstall.
+func removeRegistryKeys(dest string, cfg *Config) error {
+	if len(cfg.RegistryKeys) == 0 {
+		return nil
+	}
+	if err := platform.Registry.Remove(regKeySpecs(dest, cfg)); err != nil && !errors.Is(err, errors.ErrUnsupported) {
+		log.Printf("remove registry keys: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/errcode"
+)
+
+// Every run ends with exactly one RESULT line on stdout, whatever the
+// verbosity or UI mode, so a shell script can grep for "^RESULT " and
+// read key=value fields instead of parsing the log:
+//
+//	RESULT status=ok synced=123 skipped=4567 bytes=1.2G entry=started pid=4242
+//
+// The fields:
+//
+//	status   ok, cancelled, update-available or error
+//	code     with status=error: the stable category from internal/errcode
+//	synced, skipped, removed   file counts from the last sync pass
+//	bytes    bytes copied, with a binary suffix (K/M/G/T)
+//	entry    started (detached), waited or none
+//	pid      with entry=started: the child's process ID
+//	exit     with entry=waited: the child's exit code
+//
+// Fields may be appended over time; existing ones never change
+// meaning. Under -json the final status object replaces the line so
+// stdout stays pure JSON.
+
+// lastSync holds the stats of the most recent sync pass for the
+// RESULT line; syncOnce fills it in.
+var lastSync syncStats
+
+// resultOnce keeps the line single even on paths that report a result
+// and then exit through fatal.
+var resultOnce sync.Once
+
+// emitResult prints the RESULT line. entry is "started" (pidOrExit is
+// the child's PID), "waited" (its exit code) or "" for no launch; err
+// supplies the code field for status=error.
+func emitResult(status, entry string, pidOrExit int, err error) {
+	resultOnce.Do(func() {
+		if jsonOut {
+			return
+		}
+		line := "RESULT status=" + status
+		if err != nil {
+			line += " code=" + string(errcode.Of(err))
+		}
+		line += fmt.Sprintf(" synced=%d skipped=%d removed=%d bytes=%s",
+			lastSync.Copied, lastSync.Skipped, lastSync.Deleted, humanSize(lastSync.Bytes))
+		switch entry {
+		case "started":
+			line += fmt.Sprintf(" entry=started pid=%d", pidOrExit)
+		case "waited":
+			line += fmt.Sprintf(" entry=waited exit=%d", pidOrExit)
+		default:
+			line += " entry=none"
+		}
+		fmt.Fprintln(os.Stdout, line)
+	})
+}
+
+// humanSize renders a byte count the way the RESULT line promises:
+// one decimal and a binary suffix ("1.2G"), bare bytes below 1K.
+func humanSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGT"[exp])
+}
@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestHumanSize(t *testing.T) {
+	cases := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0"},
+		{512, "512"},
+		{1024, "1.0K"},
+		{1536, "1.5K"},
+		{10 * 1024 * 1024, "10.0M"},
+		{1288490188, "1.2G"}, // the RESULT line example
+	}
+	for _, c := range cases {
+		if got := humanSize(c.n); got != c.want {
+			t.Errorf("humanSize(%d) = %q, want %q", c.n, got, c.want)
+		}
+	}
+}
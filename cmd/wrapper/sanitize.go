@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Centralized path validation. Everything user- or publisher-
+// controlled that ends up in a filesystem path goes through here:
+// the install name, the entry path, excludes, and archive entry
+// names. The rules are enforced on every platform so a payload that
+// validates on Linux does not blow up on a Windows machine later.
+
+// reservedNames are the Windows device names that win over real
+// files in almost every API, with or without an extension.
+var reservedNames = map[string]bool{
+	"con": true, "prn": true, "aux": true, "nul": true,
+	"com1": true, "com2": true, "com3": true, "com4": true, "com5": true,
+	"com6": true, "com7": true, "com8": true, "com9": true,
+	"lpt1": true, "lpt2": true, "lpt3": true, "lpt4": true, "lpt5": true,
+	"lpt6": true, "lpt7": true, "lpt8": true, "lpt9": true,
+}
+
+// checkComponent rejects a single path component that Windows cannot
+// represent safely: reserved device names (bare or with extension)
+// and trailing dots or spaces, which the Win32 layer silently strips.
+func checkComponent(c string) error {
+	if c == "" {
+		return nil
+	}
+	if strings.HasSuffix(c, ".") || strings.HasSuffix(c, " ") {
+		return fmt.Errorf("component %q ends with a dot or space", c)
+	}
+	base := strings.ToLower(c)
+	if i := strings.IndexByte(base, '.'); i >= 0 {
+		base = base[:i]
+	}
+	if reservedNames[base] {
+		return fmt.Errorf("component %q is a reserved Windows device name", c)
+	}
+	return nil
+}
+
+// validateName checks an install name (-name). It becomes a single
+// directory component, so separators and traversal are rejected
+// outright.
+func validateName(name string) error {
+	if name == "" {
+		return nil
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("name %q must not contain path separators", name)
+	}
+	if name == "." || name == ".." {
+		return fmt.Errorf("name %q is not a valid directory name", name)
+	}
+	if strings.ContainsAny(name, ":*?\"<>|") {
+		return fmt.Errorf("name %q contains characters invalid on Windows", name)
+	}
+	return checkComponent(name)
+}
+
+// validateRelPath checks a slash-separated path that must stay inside
+// its root: the entry program, excludes, protected paths. It returns
+// the cleaned form.
+func validateRelPath(what, p string) (string, error) {
+	if p == "" {
+		return "", nil
+	}
+	if strings.Contains(p, "\\") {
+		return "", fmt.Errorf("%s %q: use forward slashes", what, p)
+	}
+	if strings.HasPrefix(p, "/") || len(p) >= 2 && p[1] == ':' {
+		return "", fmt.Errorf("%s %q: absolute paths not allowed", what, p)
+	}
+	clean := filepath.ToSlash(filepath.Clean(filepath.FromSlash(p)))
+	if clean == "." {
+		return ".", nil
+	}
+	if clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("%s %q: path escapes its root", what, p)
+	}
+	for _, c := range strings.Split(clean, "/") {
+		if err := checkComponent(c); err != nil {
+			return "", fmt.Errorf("%s %q: %w", what, p, err)
+		}
+	}
+	return clean, nil
+}
+
+// sanitizeEntryName validates an archive entry name and returns the
+// cleaned relative path. Absolute paths, drive letters, backslash
+// separators, any ".." component, reserved device names and
+// trailing-dot/space components are rejected — a hostile archive must
+// not be able to write outside the extraction root (zip-slip) or
+// plant names that misbehave on Windows.
+func sanitizeEntryName(name string) (string, error) {
+	return validateRelPath("entry", name)
+}
+
+// checkLinkTarget rejects a symlink whose target resolves outside
+// root. target is the symlink's own path inside root; link is the
+// archive-supplied target, possibly relative. Same rules as
+// verifyExtractedTree applies after external extraction.
+func checkLinkTarget(root, target, link string) error {
+	resolved := filepath.FromSlash(link)
+	// A rooted target ("/tmp") is not IsAbs on Windows but must not be
+	// joined below target's directory either.
+	if !filepath.IsAbs(resolved) && !strings.HasPrefix(resolved, string(filepath.Separator)) {
+		resolved = filepath.Join(filepath.Dir(target), resolved)
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("symlink target %q points outside the extraction root", link)
+	}
+	return nil
+}
+
+// validateScheme checks a URL scheme name for protocol registration:
+// RFC 3986 letters, digits, "+", "-" and "." only, starting with a
+// letter, so nothing hostile reaches the registry or a .desktop file.
+func validateScheme(scheme string) error {
+	if scheme == "" {
+		return fmt.Errorf("empty protocol scheme")
+	}
+	for i, r := range scheme {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '+' || r == '-' || r == '.'):
+		default:
+			return fmt.Errorf("protocol scheme %q contains invalid character %q", scheme, r)
+		}
+	}
+	return nil
+}
+
+// validateConfigPaths applies the path rules to every configured
+// value before any of them is used.
+func validateConfigPaths(cfg *Config) error {
+	if err := validateName(cfg.Name); err != nil {
+		return err
+	}
+	if cfg.Entry != "" {
+		clean, err := validateRelPath("entry program", cfg.Entry)
+		if err != nil {
+			return err
+		}
+		cfg.Entry = clean
+	}
+	for i, ex := range cfg.Excludes {
+		clean, err := validateRelPath("exclude", ex)
+		if err != nil {
+			return err
+		}
+		cfg.Excludes[i] = clean
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	valid := []string{"", "Aiwb", "my-app_2", "app.name"}
+	for _, name := range valid {
+		if err := validateName(name); err != nil {
+			t.Errorf("validateName(%q) = %v, want nil", name, err)
+		}
+	}
+	invalid := []string{
+		"a/b", `a\b`, "..", ".", "CON", "nul", "Com1.txt",
+		"trailingdot.", "trailingspace ", "a:b", "a|b",
+	}
+	for _, name := range invalid {
+		if err := validateName(name); err == nil {
+			t.Errorf("validateName(%q) = nil, want error", name)
+		}
+	}
+}
+
+func TestValidateRelPath(t *testing.T) {
+	cases := []struct {
+		in    string
+		want  string
+		valid bool
+	}{
+		{"", "", true},
+		{"bin/app.exe", "bin/app.exe", true},
+		{"./bin/app", "bin/app", true},
+		{"a/./b", "a/b", true},
+		{".", ".", true},
+		{"..", "", false},
+		{"../x", "", false},
+		{"a/../../x", "", false},
+		{"/etc/passwd", "", false},
+		{`c:/windows/system32`, "", false},
+		{`a\b`, "", false},
+		{"a/CON/b", "", false},
+		{"a/NUL.txt", "", false},
+		{"a/dir./file", "", false},
+		{"a/file ", "", false},
+	}
+	for _, c := range cases {
+		got, err := validateRelPath("test", c.in)
+		if c.valid {
+			if err != nil {
+				t.Errorf("validateRelPath(%q) = %v, want nil", c.in, err)
+			} else if got != c.want {
+				t.Errorf("validateRelPath(%q) = %q, want %q", c.in, got, c.want)
+			}
+		} else if err == nil {
+			t.Errorf("validateRelPath(%q) = %q, nil, want error", c.in, got)
+		}
+	}
+}
+
+func TestSanitizeEntryName(t *testing.T) {
+	// Archive entries go through the same rules; spot-check the
+	// classic zip-slip shapes.
+	for _, name := range []string{"../../evil", "/abs", `..\..\evil`, "ok/../../../evil"} {
+		if _, err := sanitizeEntryName(name); err == nil {
+			t.Errorf("sanitizeEntryName(%q) accepted a hostile path", name)
+		}
+	}
+	if got, err := sanitizeEntryName("dir/sub/file.bin"); err != nil || got != "dir/sub/file.bin" {
+		t.Errorf("sanitizeEntryName(benign) = %q, %v", got, err)
+	}
+}
+
+func TestCheckLinkTarget(t *testing.T) {
+	root := filepath.Join("staging", "x.extracted")
+	ok := []struct{ target, link string }{
+		{filepath.Join(root, "a"), "b"},
+		{filepath.Join(root, "sub", "a"), "../b"},
+		{filepath.Join(root, "sub", "a"), "sib/c"},
+	}
+	for _, c := range ok {
+		if err := checkLinkTarget(root, c.target, c.link); err != nil {
+			t.Errorf("checkLinkTarget(%q -> %q) = %v, want nil", c.target, c.link, err)
+		}
+	}
+	bad := []struct{ target, link string }{
+		{filepath.Join(root, "a"), "../escape"},
+		{filepath.Join(root, "sub", "a"), "../../../escape"},
+		{filepath.Join(root, "a"), "/tmp"},
+		{filepath.Join(root, "a"), ".."},
+	}
+	for _, c := range bad {
+		if err := checkLinkTarget(root, c.target, c.link); err == nil {
+			t.Errorf("checkLinkTarget(%q -> %q) accepted an out-of-root target", c.target, c.link)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+//go:build !minimal
+
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+)
+
+// The schedule subcommands hand periodic syncing to the OS scheduler —
+// Task Scheduler, a systemd user timer or launchd — so dest stays
+// fresh without the wrapper itself staying resident the way -daemon
+// does.
+
+// handleSchedule implements "wrapper schedule install|remove". Any
+// arguments after the subcommand's own flags become the wrapper flags
+// the scheduled task runs with; with none given the task just syncs
+// (-no-launch).
+func handleSchedule(cfg *Config, args []string) {
+	if len(args) == 0 || (args[0] != "install" && args[0] != "remove") {
+		fatalUsage("usage: wrapper schedule install|remove [-every 6h] [-name NAME] [wrapper flags...]")
+	}
+	verb := args[0]
+	fs := flag.NewFlagSet("schedule "+verb, flag.ExitOnError)
+	every := fs.Duration("every", 6*time.Hour, "how often the scheduled sync runs")
+	fs.StringVar(&cfg.Name, "name", cfg.Name, "task name (default: install name from the config)")
+	fs.BoolVar(&jsonOut, "json", jsonOut, jsonFlagHelp)
+	fs.Parse(args[1:])
+	if cfg.Name == "" {
+		fatalUsage("schedule needs -name or \"name\" in the config")
+	}
+
+	switch verb {
+	case "install":
+		wrapperArgs := fs.Args()
+		if len(wrapperArgs) == 0 {
+			wrapperArgs = []string{"-no-launch"}
+		}
+		if err := scheduleInstall(cfg, *every, wrapperArgs); err != nil {
+			fatal("schedule install", err)
+		}
+		log.Printf("scheduled %q to sync every %s", taskName(cfg), *every)
+	case "remove":
+		if err := scheduleRemove(cfg); err != nil {
+			fatal("schedule remove", err)
+		}
+		log.Printf("removed scheduled task %q", taskName(cfg))
+	}
+	emitJSON(statusJSON{Status: "ok"})
+}
+
+// taskName is the identifier registered with the OS scheduler.
+func taskName(cfg *Config) string {
+	return "wrapper-" + cfg.Name
+}
@@ -0,0 +1,72 @@
+//go:build darwin && !minimal
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// launchd runs the periodic sync: a per-user LaunchAgent with a
+// StartInterval, loaded immediately and at every login.
+
+func scheduleAgentPath(cfg *Config) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", "wrapper."+cfg.Name+".sync.plist"), nil
+}
+
+func scheduleInstall(cfg *Config, every time.Duration, wrapperArgs []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	path, err := scheduleAgentPath(cfg)
+	if err != nil {
+		return err
+	}
+	args := fmt.Sprintf("\t\t<string>%s</string>\n", exe)
+	for _, a := range wrapperArgs {
+		args += fmt.Sprintf("\t\t<string>%s</string>\n", a)
+	}
+	plist := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key><string>wrapper.%s.sync</string>
+	<key>ProgramArguments</key>
+	<array>
+%s	</array>
+	<key>StartInterval</key><integer>%d</integer>
+</dict>
+</plist>
+`, cfg.Name, args, int(every.Seconds()))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(plist), 0o644); err != nil {
+		return err
+	}
+	exec.Command("launchctl", "unload", path).Run()
+	if out, err := exec.Command("launchctl", "load", path).CombinedOutput(); err != nil {
+		return fmt.Errorf("launchctl load: %v\n%s", err, out)
+	}
+	return nil
+}
+
+func scheduleRemove(cfg *Config) error {
+	path, err := scheduleAgentPath(cfg)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	exec.Command("launchctl", "unload", path).Run()
+	return os.Remove(path)
+}
@@ -0,0 +1,174 @@
+//go:build linux && !minimal
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// On Linux the preferred backend is a systemd user timer; when
+// systemctl is unavailable (containers, minimal distros) a marked
+// crontab line is used instead so uninstall can find it again.
+
+func systemdUserDir() (string, error) {
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		base = filepath.Join(home, ".config")
+	}
+	return filepath.Join(base, "systemd", "user"), nil
+}
+
+func haveSystemd() bool {
+	_, err := exec.LookPath("systemctl")
+	return err == nil
+}
+
+func scheduleInstall(cfg *Config, every time.Duration, wrapperArgs []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if haveSystemd() {
+		return installSystemdTimer(cfg, exe, every, wrapperArgs)
+	}
+	return installCronLine(cfg, exe, every, wrapperArgs)
+}
+
+func scheduleRemove(cfg *Config) error {
+	if haveSystemd() {
+		if err := removeSystemdTimer(cfg); err == nil {
+			return nil
+		}
+	}
+	return removeCronLine(cfg)
+}
+
+// installSystemdTimer writes a <task>.service/.timer pair into the
+// user unit directory and enables the timer.
+func installSystemdTimer(cfg *Config, exe string, every time.Duration, wrapperArgs []string) error {
+	dir, err := systemdUserDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	task := taskName(cfg)
+	service := fmt.Sprintf(`[Unit]
+Description=Periodic sync for %s
+
+[Service]
+Type=oneshot
+ExecStart=%s %s
+`, cfg.Name, exe, strings.Join(wrapperArgs, " "))
+	timer := fmt.Sprintf(`[Unit]
+Description=Periodic sync timer for %s
+
+[Timer]
+OnBootSec=2min
+OnUnitActiveSec=%ds
+
+[Install]
+WantedBy=timers.target
+`, cfg.Name, int(every.Seconds()))
+	if err := os.WriteFile(filepath.Join(dir, task+".service"), []byte(service), 0o644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, task+".timer"), []byte(timer), 0o644); err != nil {
+		return err
+	}
+	for _, args := range [][]string{
+		{"--user", "daemon-reload"},
+		{"--user", "enable", "--now", task + ".timer"},
+	} {
+		if out, err := exec.Command("systemctl", args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("systemctl %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	return nil
+}
+
+func removeSystemdTimer(cfg *Config) error {
+	dir, err := systemdUserDir()
+	if err != nil {
+		return err
+	}
+	task := taskName(cfg)
+	if _, err := os.Stat(filepath.Join(dir, task+".timer")); err != nil {
+		return err
+	}
+	if out, err := exec.Command("systemctl", "--user", "disable", "--now", task+".timer").CombinedOutput(); err != nil {
+		return fmt.Errorf("systemctl: %v\n%s", err, out)
+	}
+	os.Remove(filepath.Join(dir, task+".timer"))
+	os.Remove(filepath.Join(dir, task+".service"))
+	exec.Command("systemctl", "--user", "daemon-reload").Run()
+	return nil
+}
+
+// cronMarker tags the generated crontab line so removal deletes only
+// what install added.
+func cronMarker(cfg *Config) string {
+	return "# " + taskName(cfg)
+}
+
+func installCronLine(cfg *Config, exe string, every time.Duration, wrapperArgs []string) error {
+	minutes := int(every.Minutes())
+	if minutes < 1 {
+		minutes = 1
+	}
+	var spec string
+	if minutes < 60 {
+		spec = fmt.Sprintf("*/%d * * * *", minutes)
+	} else {
+		spec = fmt.Sprintf("0 */%d * * *", minutes/60)
+	}
+	line := fmt.Sprintf("%s %s %s %s", spec, exe, strings.Join(wrapperArgs, " "), cronMarker(cfg))
+	current, _ := exec.Command("crontab", "-l").Output()
+	content := stripCronLines(string(current), cronMarker(cfg))
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return writeCrontab(content + line + "\n")
+}
+
+func removeCronLine(cfg *Config) error {
+	current, err := exec.Command("crontab", "-l").Output()
+	if err != nil {
+		return nil // no crontab at all
+	}
+	stripped := stripCronLines(string(current), cronMarker(cfg))
+	if stripped == string(current) {
+		return nil
+	}
+	return writeCrontab(stripped)
+}
+
+func stripCronLines(content, marker string) string {
+	var kept []string
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasSuffix(strings.TrimSpace(line), marker) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.TrimRight(strings.Join(kept, "\n"), "\n") + "\n"
+}
+
+func writeCrontab(content string) error {
+	cmd := exec.Command("crontab", "-")
+	cmd.Stdin = strings.NewReader(content)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("crontab: %v\n%s", err, out)
+	}
+	return nil
+}
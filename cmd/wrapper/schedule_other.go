@@ -0,0 +1,16 @@
+//go:build !windows && !linux && !darwin && !minimal
+
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func scheduleInstall(cfg *Config, every time.Duration, wrapperArgs []string) error {
+	return fmt.Errorf("no scheduler backend on this platform")
+}
+
+func scheduleRemove(cfg *Config) error {
+	return fmt.Errorf("no scheduler backend on this platform")
+}
@@ -0,0 +1,53 @@
+//go:build windows && !minimal
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Task Scheduler registration via schtasks. A per-user task needs no
+// elevation and survives reboots, unlike a resident -daemon.
+
+// scheduleInstall registers (or replaces) a repeating task running the
+// wrapper with the given flags.
+func scheduleInstall(cfg *Config, every time.Duration, wrapperArgs []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	minutes := int(every.Minutes())
+	if minutes < 1 {
+		minutes = 1
+	}
+	parts := []string{`"` + exe + `"`}
+	for _, a := range wrapperArgs {
+		if strings.ContainsAny(a, " \t") {
+			a = `"` + a + `"`
+		}
+		parts = append(parts, a)
+	}
+	args := []string{
+		"/Create", "/F",
+		"/SC", "MINUTE", "/MO", fmt.Sprintf("%d", minutes),
+		"/TN", taskName(cfg),
+		"/TR", strings.Join(parts, " "),
+	}
+	if out, err := exec.Command("schtasks", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("schtasks: %v\n%s", err, out)
+	}
+	return nil
+}
+
+// scheduleRemove deletes the registered task.
+func scheduleRemove(cfg *Config) error {
+	out, err := exec.Command("schtasks", "/Delete", "/F", "/TN", taskName(cfg)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("schtasks: %v\n%s", err, out)
+	}
+	return nil
+}
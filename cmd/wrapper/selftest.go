@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// "wrapper self-test" runs the real pipeline end to end against
+// throwaway directories: build a small source tree, sync it, verify
+// what landed, sync again to prove the skip path, then launch a
+// trivial child and check its exit code. When an install misbehaves
+// this separates "the wrapper is broken on this machine" from "the
+// payload is broken" before anyone files the wrong bug.
+
+// handleSelfTest implements "wrapper self-test [-keep]".
+func handleSelfTest(cfg *Config, args []string) {
+	fs := flag.NewFlagSet("self-test", flag.ExitOnError)
+	keep := fs.Bool("keep", false, "keep the temp directories for inspection")
+	fs.BoolVar(&jsonOut, "json", jsonOut, jsonFlagHelp)
+	fs.Parse(args)
+
+	failed := false
+	step := func(name string, err error) {
+		if jsonOut {
+			s := selfTestStep{Step: name, OK: err == nil}
+			if err != nil {
+				s.Error = err.Error()
+			}
+			emitJSON(s)
+		} else if err != nil {
+			fmt.Printf("FAIL %s: %v\n", name, err)
+		} else {
+			fmt.Printf("ok   %s\n", name)
+		}
+		if err != nil {
+			failed = true
+		}
+	}
+
+	src, err := os.MkdirTemp("", "wrapper-selftest-src-")
+	if err != nil {
+		fatal("self-test", err)
+	}
+	dest, err := os.MkdirTemp("", "wrapper-selftest-dest-")
+	if err != nil {
+		fatal("self-test", err)
+	}
+	if !*keep {
+		defer os.RemoveAll(src)
+		defer os.RemoveAll(dest)
+	}
+
+	entry, err := writeSelfTestTree(src)
+	step("build source tree", err)
+
+	testCfg := &Config{Source: src, Dest: dest, Mode: "mirror", Entry: entry, Wait: true}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if _, err := setupDataDir(dest, testCfg); err == nil {
+		_, err = syncOnce(ctx, dest, testCfg, syncOptions{})
+		step("sync", err)
+	} else {
+		step("sync", err)
+	}
+	step("verify dest contents", verifySelfTestTree(src, dest))
+
+	synced, err := syncOnce(ctx, dest, testCfg, syncOptions{})
+	if err == nil && synced {
+		err = fmt.Errorf("second sync copied again instead of skipping an up-to-date dest")
+	}
+	step("up-to-date skip", err)
+
+	code, err := launchEntry(dest, testCfg, nil)
+	if err == nil && code != 0 {
+		err = fmt.Errorf("child exited with code %d", code)
+	}
+	step("launch child", err)
+
+	if failed {
+		if jsonOut {
+			emitJSON(statusJSON{Status: "error", Error: "self-test failed", Exit: 1})
+		} else {
+			fmt.Println("self-test FAILED — the wrapper does not work on this machine")
+		}
+		os.Exit(1)
+	}
+	if jsonOut {
+		emitJSON(statusJSON{Status: "ok"})
+	} else {
+		fmt.Println("self-test passed")
+	}
+}
+
+// selfTestStep is one step's result under -json.
+type selfTestStep struct {
+	Step  string `json:"step"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// writeSelfTestTree fills src with a few files, a subdirectory and a
+// trivial child program, and returns the entry path.
+func writeSelfTestTree(src string) (string, error) {
+	for _, f := range []struct{ name, body string }{
+		{"data.txt", "self-test payload\n"},
+		{"sub/nested.txt", "nested file\n"},
+	} {
+		path := filepath.Join(src, filepath.FromSlash(f.name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(path, []byte(f.body), 0o644); err != nil {
+			return "", err
+		}
+	}
+	// The child only has to start and exit cleanly; a script avoids
+	// shipping a compiled helper in the wrapper binary.
+	if runtime.GOOS == "windows" {
+		return "child.cmd", os.WriteFile(filepath.Join(src, "child.cmd"), []byte("@exit /b 0\r\n"), 0o644)
+	}
+	return "child.sh", os.WriteFile(filepath.Join(src, "child.sh"), []byte("#!/bin/sh\nexit 0\n"), 0o755)
+}
+
+// verifySelfTestTree checks every source file arrived in dest intact.
+func verifySelfTestTree(src, dest string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		want, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		got, err := os.ReadFile(filepath.Join(dest, rel))
+		if err != nil {
+			return err
+		}
+		if string(got) != string(want) {
+			return fmt.Errorf("%s differs from the source", rel)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// Shared-cache mode syncs each payload version once into a
+// machine-wide cache and then populates the per-user dest with
+// hardlinks into it, so a multi-user machine stores one copy of the
+// version instead of one per user. Hardlinking falls back to a plain
+// copy when the cache and dest sit on different volumes.
+
+// sharedCacheDir is the machine-wide home of one payload version.
+func sharedCacheDir(cfg *Config, digest string) string {
+	return filepath.Join(machineInstallRoot(), "wrapper-cache", cfg.Name, digest)
+}
+
+// syncViaSharedCache fills the cache for this digest if some other
+// user has not already, then links dest's files to the cached copies.
+func syncViaSharedCache(ctx context.Context, source, dest, digest string, cfg *Config, stats *syncStats) error {
+	if cfg.Name == "" {
+		return fmt.Errorf("shared_cache needs -name")
+	}
+	cache := sharedCacheDir(cfg, digest)
+	marker := filepath.Join(cache, completeMarker)
+	if _, err := os.Stat(marker); err != nil {
+		var cacheStats syncStats
+		if err := syncDir(ctx, source, cache, cfg, &cacheStats); err != nil {
+			return fmt.Errorf("fill shared cache: %w", err)
+		}
+		if err := os.WriteFile(marker, []byte("ok\n"), 0o644); err != nil {
+			return fmt.Errorf("mark shared cache complete: %w", err)
+		}
+		log.Printf("cached version %.12s… in %s (%d files)", digest, cache, cacheStats.Copied)
+	}
+	return linkTree(cache, dest, cfg, stats)
+}
+
+// linkTree mirrors the cached version into dest via hardlinks. Files
+// already linked to the right cache copy are skipped; in mirror mode
+// strays in dest are removed afterwards like a normal sync.
+func linkTree(cache, dest string, cfg *Config, stats *syncStats) error {
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return fmt.Errorf("create destination: %w", err)
+	}
+	seen := make(map[string]bool)
+	err := filepath.Walk(cache, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(cache, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." || rel == completeMarker {
+			return nil
+		}
+		seen[filepath.ToSlash(rel)] = true
+		dp := filepath.Join(dest, rel)
+		if info.IsDir() {
+			return os.MkdirAll(dp, 0o755)
+		}
+		if di, err := os.Stat(dp); err == nil && (os.SameFile(di, info) || protected(rel, cfg.Protected)) {
+			stats.Skipped++
+			return nil
+		}
+		os.Remove(dp)
+		if err := os.Link(path, dp); err != nil {
+			// Different volume or a filesystem without hardlinks:
+			// degrade to a copy for this file.
+			if cerr := copyFile(path, dp, info); cerr != nil {
+				return fmt.Errorf("link %s: %w", rel, cerr)
+			}
+		}
+		stats.Copied++
+		stats.Bytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if cfg.Mode == "mirror" {
+		return mirrorDelete(dest, seen, cfg, stats)
+	}
+	return nil
+}
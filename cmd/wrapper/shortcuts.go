@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/platform"
+)
+
+func shortcutName(cfg *Config) string {
+	if cfg.Shortcuts.DisplayName != "" {
+		return cfg.Shortcuts.DisplayName
+	}
+	return cfg.Name
+}
+
+// shortcutSpec translates the config into the platform-neutral spec
+// the integration layer consumes.
+func shortcutSpec(dest string, cfg *Config) (platform.ShortcutSpec, error) {
+	sc := &cfg.Shortcuts
+	name := shortcutName(cfg)
+	if name == "" {
+		return platform.ShortcutSpec{}, fmt.Errorf("shortcuts need -name or shortcuts.display_name")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return platform.ShortcutSpec{}, err
+	}
+	icon := filepath.Join(dest, filepath.FromSlash(cfg.Entry))
+	if sc.Icon != "" {
+		icon = filepath.Join(dest, filepath.FromSlash(sc.Icon))
+	}
+	return platform.ShortcutSpec{
+		Name:      name,
+		StartMenu: sc.StartMenu,
+		Desktop:   sc.Desktop,
+		Target:    exe,
+		Args:      strings.Join(sc.Args, " "),
+		WorkDir:   dest,
+		Icon:      icon,
+	}, nil
+}
+
+// createShortcuts writes the configured Start Menu / Desktop
+// shortcuts pointing at the wrapper, so relaunches always sync first.
+func createShortcuts(dest string, cfg *Config) error {
+	if !cfg.Shortcuts.StartMenu && !cfg.Shortcuts.Desktop {
+		return nil
+	}
+	spec, err := shortcutSpec(dest, cfg)
+	if err != nil {
+		return err
+	}
+	created, err := platform.Shortcuts.Create(spec)
+	for _, link := range created {
+		recordChange("file", link)
+		log.Printf("created shortcut %s", link)
+	}
+	if errors.Is(err, errors.ErrUnsupported) {
+		log.Printf("shortcuts are only created on Windows; ignoring")
+		return nil
+	}
+	return err
+}
+
+// removeShortcuts deletes the shortcuts createShortcuts would create.
+func removeShortcuts(cfg *Config) error {
+	if shortcutName(cfg) == "" {
+		return nil
+	}
+	spec, err := shortcutSpec("", cfg)
+	if err != nil {
+		return err
+	}
+	return platform.Shortcuts.Remove(spec)
+}
@@ -0,0 +1,155 @@
+//go:build !minimal
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// minisign wire format: a decoded public key is "Ed" + 8-byte key id
+// + 32-byte ed25519 key; a decoded signature is a 2-byte algorithm
+// ("Ed" = raw content, "ED" = Blake2b-512 prehash) + 8-byte key id +
+// 64-byte ed25519 signature. Keys are pinned in the config as the
+// base64 line from the .pub file.
+
+type minisignKey struct {
+	id  [8]byte
+	key ed25519.PublicKey
+}
+
+// parseMinisignKey decodes the base64 payload line of a minisign
+// public key file.
+func parseMinisignKey(s string) (*minisignKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("bad base64: %w", err)
+	}
+	if len(raw) != 2+8+ed25519.PublicKeySize || string(raw[:2]) != "Ed" {
+		return nil, fmt.Errorf("not an ed25519 minisign public key")
+	}
+	k := &minisignKey{key: ed25519.PublicKey(raw[10:])}
+	copy(k.id[:], raw[2:10])
+	return k, nil
+}
+
+// verifySignature checks a downloaded payload against a minisign
+// detached signature using the public keys pinned in the config. The
+// signature comes from a .minisig sidecar next to the source URL
+// (or next to the local file for local archive sources). Configured
+// keys make signatures mandatory: no signature means no install.
+func verifySignature(ctx context.Context, local, sourceURL string, cfg *Config) error {
+	if len(cfg.MinisignKeys) == 0 {
+		return nil
+	}
+	keys := make([]*minisignKey, 0, len(cfg.MinisignKeys))
+	for _, s := range cfg.MinisignKeys {
+		k, err := parseMinisignKey(s)
+		if err != nil {
+			return fmt.Errorf("pinned minisign key %q: %w", s, err)
+		}
+		keys = append(keys, k)
+	}
+	sig, err := fetchMinisig(ctx, local, sourceURL)
+	if err != nil {
+		return fmt.Errorf("minisign keys are pinned but no signature found: %w", err)
+	}
+	alg, keyID, rawSig, err := parseMinisig(sig)
+	if err != nil {
+		return err
+	}
+	var key *minisignKey
+	for _, k := range keys {
+		if bytes.Equal(k.id[:], keyID) {
+			key = k
+			break
+		}
+	}
+	if key == nil {
+		return fmt.Errorf("signature key id %x does not match any pinned key", keyID)
+	}
+	msg, err := signedMessage(local, alg)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(key.key, msg, rawSig) {
+		os.Remove(local)
+		return fmt.Errorf("signature verification failed for %s (file removed)", filepath.Base(local))
+	}
+	log.Printf("signature verified for %s", filepath.Base(local))
+	return nil
+}
+
+// parseMinisig extracts algorithm, key id and signature bytes from a
+// .minisig file's contents.
+func parseMinisig(data []byte) (alg string, keyID, sig []byte, err error) {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "untrusted comment:") || strings.HasPrefix(line, "trusted comment:") {
+			continue
+		}
+		raw, derr := base64.StdEncoding.DecodeString(line)
+		if derr != nil {
+			continue
+		}
+		if len(raw) != 2+8+ed25519.SignatureSize {
+			continue
+		}
+		alg = string(raw[:2])
+		if alg != "Ed" && alg != "ED" {
+			continue
+		}
+		return alg, raw[2:10], raw[10:], nil
+	}
+	return "", nil, nil, fmt.Errorf("no minisign signature line found")
+}
+
+// signedMessage returns the bytes the signature covers: the raw file
+// for legacy "Ed" signatures, its Blake2b-512 digest for "ED".
+func signedMessage(local, alg string) ([]byte, error) {
+	f, err := os.Open(local)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if alg == "ED" {
+		h, _ := blake2b.New512(nil)
+		if _, err := io.Copy(h, f); err != nil {
+			return nil, err
+		}
+		return h.Sum(nil), nil
+	}
+	return io.ReadAll(f)
+}
+
+// fetchMinisig loads the detached signature: <url>.minisig for URL
+// sources, <file>.minisig on disk for local ones.
+func fetchMinisig(ctx context.Context, local, sourceURL string) ([]byte, error) {
+	if isURL(sourceURL) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL+".minisig", nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetch %s.minisig: status %s", sourceURL, resp.Status)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	}
+	return os.ReadFile(local + ".minisig")
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// destState is the wrapper's per-dest state file: which migrations
+// have run, and other once-only bookkeeping that must survive
+// updates. It sits next to the version stamp and is covered by the
+// .wrapper- metadata prefix, so mirror mode leaves it alone.
+const stateFileName = ".wrapper-state.json"
+
+type destState struct {
+	// Migrations maps migration IDs to their recorded result.
+	Migrations map[string]string `json:"migrations,omitempty"`
+
+	// FirstRunAt is when this dest was first launched; zero means it
+	// never has been.
+	FirstRunAt time.Time `json:"first_run_at,omitempty"`
+}
+
+// loadState returns an empty state when none exists yet.
+func loadState(dest string) *destState {
+	st := &destState{}
+	data, err := os.ReadFile(filepath.Join(dest, stateFileName))
+	if err != nil {
+		return st
+	}
+	json.Unmarshal(data, st)
+	return st
+}
+
+func saveState(dest string, st *destState) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dest, stateFileName), append(data, '\n'), 0o644)
+}
@@ -0,0 +1,73 @@
+//go:build !minimal
+
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// The status endpoint is a read-only HTTP counterpart to the control
+// socket, for monitoring agents and for the app itself: /healthz for
+// liveness probes and /status for a JSON snapshot. It binds whatever
+// -status-addr says; the default of a loopback address keeps it off
+// the network.
+
+// statusSnapshot is the /status response body.
+type statusSnapshot struct {
+	Version    string    `json:"version"`
+	ChildPID   int       `json:"child_pid"`
+	ChildState string    `json:"child_state"`
+	LastSync   time.Time `json:"last_sync"`
+	LastResult string    `json:"last_result"`
+
+	// LastErrorCode is the errcode classification of the last failed
+	// sync; empty after a success.
+	LastErrorCode string `json:"last_error_code,omitempty"`
+}
+
+func (st *daemonState) snapshot() statusSnapshot {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	childState := "not-launched"
+	if st.childPID > 0 {
+		childState = "running"
+	}
+	return statusSnapshot{
+		Version:    st.version,
+		ChildPID:   st.childPID,
+		ChildState: childState,
+		LastSync:   st.lastSync,
+		LastResult: st.lastResult,
+
+		LastErrorCode: string(st.lastErrCode),
+	}
+}
+
+// startStatusServer begins serving and returns the listener so the
+// caller can close it; the bound address is logged because -status-addr
+// usually asks for an ephemeral port.
+func startStatusServer(addr string, state *daemonState) (net.Listener, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(state.snapshot())
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		metrics.write(w)
+	})
+	go http.Serve(l, mux)
+	log.Printf("status endpoint on http://%s", l.Addr())
+	return l, nil
+}
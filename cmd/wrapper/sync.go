@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/syncer"
+)
+
+// The copy engine lives in pkg/syncer so other installers can embed
+// it; these aliases keep this package reading as it always has.
+type syncStats = syncer.Stats
+
+var (
+	syncDir          = syncer.SyncDir
+	syncLayers       = syncer.SyncLayers
+	mirrorDelete     = syncer.MirrorDelete
+	copyFile         = syncer.CopyFile
+	protected        = syncer.Protected
+	excluded         = syncer.Excluded
+	combineDigests   = syncer.CombineDigests
+	removePayload    = syncer.RemovePayload
+	sourceDigest     = syncer.SourceDigest
+	dedupDest        = syncer.DedupDest
+	readVersion      = syncer.ReadVersion
+	writeVersion     = syncer.WriteVersion
+	fileSHA256       = syncer.FileSHA256
+	recordFileDigest = syncer.RecordFileDigest
+	hashBufPool      = &syncer.HashBufPool
+)
+
+const (
+	versionFileName    = syncer.VersionFileName
+	wrapperMetaPrefix  = syncer.MetaPrefix
+	chunkPartialSuffix = syncer.ChunkPartialSuffix
+	chunkJournalSuffix = syncer.ChunkJournalSuffix
+	downloadsDirName   = syncer.DownloadsDirName
+	stagingDirPrefix   = syncer.StagingDirPrefix
+
+	// completeMarker flags a staging dir whose payload downloaded and
+	// verified fully; dirs without it are resumed or garbage.
+	completeMarker = ".complete"
+)
+
+// destWritable probes whether this process can create files in dest,
+// which decides if a machine-scope sync needs elevation.
+func destWritable(dest string) bool {
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return false
+	}
+	probe := filepath.Join(dest, ".wrapper-write-probe")
+	f, err := os.OpenFile(probe, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(probe)
+	return true
+}
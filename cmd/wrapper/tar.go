@@ -0,0 +1,164 @@
+//go:build !minimal
+
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// extractTar unpacks a tarball (plain, gzip, xz or zstd compressed)
+// into dir. Mode bits, mtimes, symlinks and hardlinks from the
+// archive are preserved; hardlinks fall back to copies on filesystems
+// (or platforms) where linking fails.
+func extractTar(archive, kind, dir string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return extractTarStream(f, kind, dir)
+}
+
+// extractTarStream is extractTar for any reader, so remote tarballs
+// can be unpacked while still downloading.
+func extractTarStream(src io.Reader, kind, dir string) error {
+	var r io.Reader = src
+	switch kind {
+	case "tar":
+	case "tar.gz":
+		gz, err := gzip.NewReader(src)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	case "tar.xz":
+		xr, err := xz.NewReader(src)
+		if err != nil {
+			return err
+		}
+		r = xr
+	case "tar.zst":
+		zr, err := zstd.NewReader(src)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		return fmt.Errorf("unsupported tar variant %q", kind)
+	}
+
+	tr := tar.NewReader(r)
+	// Symlinks are collected and created after everything else, like
+	// extractZip does: created in stream order, an early symlink would
+	// let a later regular entry write through it outside dir.
+	type pendingLink struct {
+		name, target, link string
+	}
+	var links []pendingLink
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		rel, err := sanitizeEntryName(hdr.Name)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			continue
+		}
+		target := filepath.Join(dir, filepath.FromSlash(rel))
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, dirPerm(hdr.FileInfo().Mode())); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, filePerm(hdr.FileInfo().Mode()))
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			if err := out.Close(); err != nil {
+				return err
+			}
+			if !hdr.ModTime.IsZero() {
+				os.Chtimes(target, hdr.ModTime, hdr.ModTime)
+			}
+		case tar.TypeSymlink:
+			// The target must stay inside the extraction root — the
+			// same zip-slip rules verifyExtractedTree enforces for 7z.
+			if err := checkLinkTarget(dir, target, hdr.Linkname); err != nil {
+				return fmt.Errorf("entry %q: %w", hdr.Name, err)
+			}
+			links = append(links, pendingLink{hdr.Name, target, hdr.Linkname})
+		case tar.TypeLink:
+			linkRel, err := sanitizeEntryName(hdr.Linkname)
+			if err != nil {
+				return err
+			}
+			linkTarget := filepath.Join(dir, filepath.FromSlash(linkRel))
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			os.Remove(target)
+			if err := os.Link(linkTarget, target); err != nil {
+				// Fall back to a copy; contents matter more than the
+				// link structure for launching the payload.
+				if cerr := copyPlainFile(linkTarget, target); cerr != nil {
+					return fmt.Errorf("entry %q: hardlink failed (%v) and copy fallback failed: %w", hdr.Name, err, cerr)
+				}
+			}
+		case tar.TypeXGlobalHeader:
+			// pax global header (e.g. from git archive): ignore.
+		default:
+			return fmt.Errorf("entry %q: unsupported tar entry type %d", hdr.Name, hdr.Typeflag)
+		}
+	}
+	for _, l := range links {
+		if err := os.MkdirAll(filepath.Dir(l.target), 0o755); err != nil {
+			return err
+		}
+		os.Remove(l.target)
+		if err := os.Symlink(l.link, l.target); err != nil {
+			if runtime.GOOS == "windows" {
+				// Creating symlinks usually needs a privilege on
+				// Windows; a payload with symlinks is a packaging
+				// problem there, so fail with a pointed message.
+				return fmt.Errorf("entry %q: cannot create symlink on Windows (repackage without symlinks): %w", l.name, err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// copyPlainFile is a minimal same-filesystem copy used as the
+// hardlink fallback during extraction.
+func copyPlainFile(src, dest string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	return copyFile(src, dest, info)
+}
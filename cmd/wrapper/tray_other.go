@@ -0,0 +1,14 @@
+//go:build !windows && !minimal
+
+package main
+
+import (
+	"context"
+	"log"
+)
+
+// Tray integration only exists on Windows; desktop Linux and macOS
+// have no toolkit-free tray API worth shelling out to.
+func startTray(ctx context.Context, dest string, cfg *Config, reqs *controlRequests) {
+	log.Printf("tray: only available on Windows; ignoring")
+}
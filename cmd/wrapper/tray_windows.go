@@ -0,0 +1,252 @@
+//go:build windows && !minimal
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// The tray icon gives a -daemon install a face: hover shows the last
+// sync result, the menu offers the same actions "wrapper ctl" does.
+// Everything below is plain user32/shell32 — a tray icon is one hidden
+// window, one Shell_NotifyIcon registration and a message loop, which
+// does not justify a GUI dependency.
+
+var (
+	user32              = windows.NewLazySystemDLL("user32.dll")
+	shell32             = windows.NewLazySystemDLL("shell32.dll")
+	procRegisterClassEx = user32.NewProc("RegisterClassExW")
+	procCreateWindowEx  = user32.NewProc("CreateWindowExW")
+	procDefWindowProc   = user32.NewProc("DefWindowProcW")
+	procDestroyWindow   = user32.NewProc("DestroyWindow")
+	procGetMessage      = user32.NewProc("GetMessageW")
+	procDispatchMessage = user32.NewProc("DispatchMessageW")
+	procPostQuitMessage = user32.NewProc("PostQuitMessage")
+	procPostMessage     = user32.NewProc("PostMessageW")
+	procCreatePopupMenu = user32.NewProc("CreatePopupMenu")
+	procDestroyMenu     = user32.NewProc("DestroyMenu")
+	procAppendMenu      = user32.NewProc("AppendMenuW")
+	procTrackPopupMenu  = user32.NewProc("TrackPopupMenu")
+	procGetCursorPos    = user32.NewProc("GetCursorPos")
+	procSetForeground   = user32.NewProc("SetForegroundWindow")
+	procSetTimer        = user32.NewProc("SetTimer")
+	procLoadIcon        = user32.NewProc("LoadIconW")
+	procShellNotifyIcon = shell32.NewProc("Shell_NotifyIconW")
+)
+
+const (
+	wmDestroy      = 0x0002
+	wmClose        = 0x0010
+	wmTimer        = 0x0113
+	wmCommand      = 0x0111
+	wmRButtonUp    = 0x0205
+	wmLButtonUp    = 0x0202
+	wmTrayIcon     = 0x8001 // WM_APP + 1
+	nifMessage     = 0x1
+	nifIcon        = 0x2
+	nifTip         = 0x4
+	nimAdd         = 0
+	nimModify      = 1
+	nimDelete      = 2
+	mfString       = 0x0
+	mfSeparator    = 0x800
+	tpmReturnCmd   = 0x100
+	idiApplication = 32512
+
+	menuSync    = 1
+	menuLogs    = 2
+	menuRestart = 3
+	menuExit    = 4
+)
+
+type notifyIconData struct {
+	CbSize           uint32
+	HWnd             windows.Handle
+	UID              uint32
+	UFlags           uint32
+	UCallbackMessage uint32
+	HIcon            windows.Handle
+	SzTip            [128]uint16
+	DwState          uint32
+	DwStateMask      uint32
+	SzInfo           [256]uint16
+	UVersion         uint32
+	SzInfoTitle      [64]uint16
+	DwInfoFlags      uint32
+	GUIDItem         windows.GUID
+	HBalloonIcon     windows.Handle
+}
+
+type wndClassEx struct {
+	CbSize        uint32
+	Style         uint32
+	LpfnWndProc   uintptr
+	CbClsExtra    int32
+	CbWndExtra    int32
+	HInstance     windows.Handle
+	HIcon         windows.Handle
+	HCursor       windows.Handle
+	HbrBackground windows.Handle
+	LpszMenuName  *uint16
+	LpszClassName *uint16
+	HIconSm       windows.Handle
+}
+
+type winMsg struct {
+	HWnd    windows.Handle
+	Message uint32
+	WParam  uintptr
+	LParam  uintptr
+	Time    uint32
+	Pt      struct{ X, Y int32 }
+}
+
+// startTray runs the icon until the context ends or Exit is chosen.
+// All user32 calls stay on one locked OS thread, as the API requires.
+func startTray(ctx context.Context, dest string, cfg *Config, reqs *controlRequests) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	var hwndShared atomic.Uintptr
+	title := notifyTitle(cfg)
+
+	nid := &notifyIconData{UID: 1, UFlags: nifMessage | nifIcon | nifTip, UCallbackMessage: wmTrayIcon}
+	nid.CbSize = uint32(unsafe.Sizeof(*nid))
+	icon, _, _ := procLoadIcon.Call(0, idiApplication)
+	nid.HIcon = windows.Handle(icon)
+
+	setTip := func(s string) {
+		tip, err := windows.UTF16FromString(title + ": " + s)
+		if err != nil {
+			return
+		}
+		for i := range nid.SzTip {
+			nid.SzTip[i] = 0
+		}
+		copy(nid.SzTip[:len(nid.SzTip)-1], tip)
+	}
+	setTip(reqs.state.summary())
+
+	wndProc := func(hwnd windows.Handle, msg uint32, wParam, lParam uintptr) uintptr {
+		switch msg {
+		case wmTrayIcon:
+			if lParam == wmRButtonUp || lParam == wmLButtonUp {
+				switch trayMenu(hwnd) {
+				case menuSync:
+					reqs.requestSync()
+				case menuLogs:
+					openLogsFolder(dest)
+				case menuRestart:
+					reqs.requestRestart()
+				case menuExit:
+					reqs.requestShutdown()
+					procDestroyWindow.Call(uintptr(hwnd))
+				}
+			}
+		case wmTimer:
+			setTip(reqs.state.summary())
+			nid.HWnd = hwnd
+			procShellNotifyIcon.Call(nimModify, uintptr(unsafe.Pointer(nid)))
+		case wmClose:
+			procDestroyWindow.Call(uintptr(hwnd))
+		case wmDestroy:
+			nid.HWnd = hwnd
+			procShellNotifyIcon.Call(nimDelete, uintptr(unsafe.Pointer(nid)))
+			procPostQuitMessage.Call(0)
+		default:
+			ret, _, _ := procDefWindowProc.Call(uintptr(hwnd), uintptr(msg), wParam, lParam)
+			return ret
+		}
+		return 0
+	}
+
+	className, _ := windows.UTF16PtrFromString("wrapperTray")
+	wc := &wndClassEx{
+		LpfnWndProc:   syscall.NewCallback(wndProc),
+		LpszClassName: className,
+	}
+	wc.CbSize = uint32(unsafe.Sizeof(*wc))
+	if atom, _, err := procRegisterClassEx.Call(uintptr(unsafe.Pointer(wc))); atom == 0 {
+		log.Printf("tray: register class: %v", err)
+		return
+	}
+	hwnd, _, err := procCreateWindowEx.Call(0, uintptr(unsafe.Pointer(className)), 0,
+		0, 0, 0, 0, 0, 0, 0, 0, 0)
+	if hwnd == 0 {
+		log.Printf("tray: create window: %v", err)
+		return
+	}
+	hwndShared.Store(hwnd)
+
+	nid.HWnd = windows.Handle(hwnd)
+	if ok, _, err := procShellNotifyIcon.Call(nimAdd, uintptr(unsafe.Pointer(nid))); ok == 0 {
+		log.Printf("tray: Shell_NotifyIcon: %v", err)
+		procDestroyWindow.Call(hwnd)
+		return
+	}
+	procSetTimer.Call(hwnd, 1, 5000, 0)
+
+	// A daemon shut down by signal or ctl must still remove its icon.
+	go func() {
+		<-ctx.Done()
+		if h := hwndShared.Load(); h != 0 {
+			procPostMessage.Call(h, wmClose, 0, 0)
+		}
+	}()
+
+	var m winMsg
+	for {
+		ret, _, _ := procGetMessage.Call(uintptr(unsafe.Pointer(&m)), 0, 0, 0)
+		if int32(ret) <= 0 {
+			return
+		}
+		procDispatchMessage.Call(uintptr(unsafe.Pointer(&m)))
+	}
+}
+
+// trayMenu shows the popup at the cursor and returns the chosen id.
+func trayMenu(hwnd windows.Handle) int {
+	menu, _, _ := procCreatePopupMenu.Call()
+	if menu == 0 {
+		return 0
+	}
+	defer procDestroyMenu.Call(menu)
+	appendItem := func(id uintptr, label string) {
+		text, _ := windows.UTF16PtrFromString(label)
+		procAppendMenu.Call(menu, mfString, id, uintptr(unsafe.Pointer(text)))
+	}
+	appendItem(menuSync, "Check for updates now")
+	appendItem(menuLogs, "Open logs")
+	appendItem(menuRestart, "Restart app")
+	procAppendMenu.Call(menu, mfSeparator, 0, 0)
+	appendItem(menuExit, "Exit")
+
+	var pt struct{ X, Y int32 }
+	procGetCursorPos.Call(uintptr(unsafe.Pointer(&pt)))
+	// Required so the menu closes when the user clicks elsewhere.
+	procSetForeground.Call(uintptr(hwnd))
+	cmd, _, _ := procTrackPopupMenu.Call(menu, tpmReturnCmd,
+		uintptr(pt.X), uintptr(pt.Y), 0, uintptr(hwnd), 0)
+	return int(cmd)
+}
+
+// openLogsFolder shows the capture directory when it exists, else dest.
+func openLogsFolder(dest string) {
+	dir := filepath.Join(dest, ".wrapper-logs")
+	if _, err := os.Stat(dir); err != nil {
+		dir = dest
+	}
+	if err := exec.Command("explorer", dir).Start(); err != nil {
+		log.Printf("tray: open logs: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/syncer"
+
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TUI progress: -tui redraws a small in-place status block instead of
+// scrolling log lines — per-worker file progress, overall totals, the
+// most recent warnings, and a cancel key. For a multi-gigabyte
+// first install this is the difference between "it is working" and a
+// frozen-looking console. Plain ANSI escapes only; anything fancier
+// would drag in a toolkit for a five-line display.
+
+// tuiWarnings is how many recent warnings stay on screen.
+const tuiWarnings = 3
+
+// tuiView is the Events sink behind the display. File callbacks come
+// from the parallel copy workers, so everything is under one mutex.
+type tuiView struct {
+	mu       sync.Mutex
+	inflight map[string]int64
+	files    int64
+	bytes    int64
+	phase    string
+	warnings []string
+	lines    int // lines drawn by the previous render
+}
+
+func (t *tuiView) OnPhaseChange(phase string) {
+	t.mu.Lock()
+	t.phase = phase
+	t.mu.Unlock()
+}
+
+func (t *tuiView) OnFileStart(rel string, size int64) {
+	t.mu.Lock()
+	t.inflight[rel] = size
+	t.mu.Unlock()
+}
+
+func (t *tuiView) OnFileDone(rel string, size int64, err error) {
+	t.mu.Lock()
+	delete(t.inflight, rel)
+	if err == nil {
+		t.files++
+		t.bytes += size
+	}
+	t.mu.Unlock()
+}
+
+func (t *tuiView) OnError(err error) {
+	t.mu.Lock()
+	t.warnings = append(t.warnings, err.Error())
+	if len(t.warnings) > tuiWarnings {
+		t.warnings = t.warnings[len(t.warnings)-tuiWarnings:]
+	}
+	t.mu.Unlock()
+}
+
+// render redraws the block in place: cursor up over the previous
+// frame, then one cleared line per row.
+func (t *tuiView) render() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var b strings.Builder
+	if t.lines > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", t.lines)
+	}
+	row := func(format string, args ...any) {
+		b.WriteString("\x1b[2K")
+		fmt.Fprintf(&b, format, args...)
+		b.WriteByte('\n')
+	}
+	phase := t.phase
+	if phase == "" {
+		phase = "preparing"
+	}
+	row("%s: %d files, %.1f MB  (q + Enter or Ctrl+C cancels)", phase, t.files, float64(t.bytes)/1e6)
+	active := make([]string, 0, len(t.inflight))
+	for rel := range t.inflight {
+		active = append(active, rel)
+	}
+	sort.Strings(active)
+	for _, rel := range active {
+		row("  %-50.50s %8.1f MB", rel, float64(t.inflight[rel])/1e6)
+	}
+	for _, w := range t.warnings {
+		row("  ! %.70s", w)
+	}
+	t.lines = 1 + len(active) + len(t.warnings)
+	os.Stderr.WriteString(b.String())
+}
+
+// startTUI installs the sink and begins redrawing; cancel fires when
+// the user presses the cancel key. The returned stop function draws a
+// last frame and restores the log-based sink.
+func startTUI(cancel func()) (stop func()) {
+	view := &tuiView{inflight: make(map[string]int64)}
+	syncer.SetEvents(view)
+	ticker := time.NewTicker(200 * time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				view.render()
+			}
+		}
+	}()
+	go func() {
+		// Line-buffered on purpose: raw terminal modes are not worth a
+		// dependency, and Ctrl+C already works through the signal
+		// context.
+		in := bufio.NewScanner(os.Stdin)
+		for in.Scan() {
+			if strings.TrimSpace(in.Text()) == "q" {
+				cancel()
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+		view.render()
+		syncer.SetEvents(cliEvents{})
+	}
+}
@@ -0,0 +1,95 @@
+//go:build !minimal
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// A feed is a small JSON document the publisher keeps next to the
+// payload describing the latest release:
+//
+//	{"version": "<payload digest>", "url": "https://…/payload.zip",
+//	 "sha256": "…", "notes": "what changed"}
+//
+// "github:owner/repo" is also accepted and resolved through the
+// GitHub releases API, using the release tag as the version.
+type feedInfo struct {
+	Version string `json:"version"`
+	URL     string `json:"url"`
+	SHA256  string `json:"sha256"`
+	Notes   string `json:"notes"`
+}
+
+// fetchFeed retrieves and parses the configured feed.
+func fetchFeed(feed string) (*feedInfo, error) {
+	if repo, ok := strings.CutPrefix(feed, "github:"); ok {
+		return fetchGitHubLatest(repo)
+	}
+	client := &http.Client{Transport: httpTransport, Timeout: 30 * time.Second}
+	resp, err := client.Get(feed)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch feed: status %s", resp.Status)
+	}
+	var info feedInfo
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&info); err != nil {
+		return nil, fmt.Errorf("parse feed: %w", err)
+	}
+	if info.Version == "" {
+		return nil, fmt.Errorf("feed has no version field")
+	}
+	return &info, nil
+}
+
+// fetchGitHubLatest resolves owner/repo's latest release. The tag
+// name stands in for the payload digest and the first asset is the
+// download URL.
+func fetchGitHubLatest(repo string) (*feedInfo, error) {
+	client := &http.Client{Transport: httpTransport, Timeout: 30 * time.Second}
+	resp, err := client.Get("https://api.github.com/repos/" + repo + "/releases/latest")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("github releases for %s: status %s", repo, resp.Status)
+	}
+	var rel struct {
+		TagName string `json:"tag_name"`
+		Body    string `json:"body"`
+		Assets  []struct {
+			BrowserDownloadURL string `json:"browser_download_url"`
+		} `json:"assets"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&rel); err != nil {
+		return nil, err
+	}
+	if rel.TagName == "" {
+		return nil, fmt.Errorf("github releases for %s: no tag name", repo)
+	}
+	info := &feedInfo{Version: rel.TagName, Notes: rel.Body}
+	if len(rel.Assets) > 0 {
+		info.URL = rel.Assets[0].BrowserDownloadURL
+	}
+	return info, nil
+}
+
+// checkUpdate compares the installed version against the feed and
+// reports whether an update is pending.
+func checkUpdate(dest, feed string) (latest *feedInfo, pending bool, err error) {
+	latest, err = fetchFeed(feed)
+	if err != nil {
+		return nil, false, err
+	}
+	installed := readVersion(dest)
+	return latest, installed != latest.Version, nil
+}
@@ -0,0 +1,79 @@
+//go:build !minimal
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// verifyDownload checks a downloaded payload against a SHA-256
+// before anything is installed from it. The expected digest comes
+// from the config if set, otherwise from a .sha256 sidecar next to
+// the source URL. With required set, a payload with no obtainable
+// digest is refused outright.
+func verifyDownload(ctx context.Context, local, sourceURL string, cfg *Config, required bool) error {
+	expected := strings.ToLower(strings.TrimSpace(cfg.SHA256))
+	if expected == "" {
+		var err error
+		expected, err = fetchSidecarSHA256(ctx, sourceURL)
+		if err != nil {
+			log.Printf("no .sha256 sidecar for %s: %v", sourceURL, err)
+		}
+	}
+	if expected == "" {
+		if required {
+			return fmt.Errorf("no SHA-256 available for %s and -require-checksum is set", sourceURL)
+		}
+		return nil
+	}
+	if len(expected) != sha256.Size*2 {
+		return fmt.Errorf("expected SHA-256 %q is not a 64-char hex digest", expected)
+	}
+	actual, err := fileSHA256(local)
+	if err != nil {
+		return fmt.Errorf("hash %s: %w", local, err)
+	}
+	if actual != expected {
+		// A mismatched file must never be picked up by a later run.
+		os.Remove(local)
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s (file removed)",
+			filepath.Base(local), actual, expected)
+	}
+	log.Printf("checksum verified for %s", filepath.Base(local))
+	return nil
+}
+
+// fetchSidecarSHA256 downloads <url>.sha256 and extracts the digest.
+// Both bare-digest and "digest  filename" (sha256sum output) formats
+// are accepted.
+func fetchSidecarSHA256(ctx context.Context, sourceURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL+".sha256", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty sidecar")
+	}
+	return strings.ToLower(fields[0]), nil
+}
@@ -0,0 +1,109 @@
+//go:build !minimal
+
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watch mode keeps resyncing after the initial run, which is handy
+// while developing a payload: rebuild into the source directory and
+// dest follows automatically. Only local directory sources can be
+// watched; URL and archive sources have nothing for fsnotify to see.
+
+// watchAndSync blocks, re-running the sync whenever changes under
+// source settle. A rebuild or cache re-extraction produces hundreds of
+// events in a burst; each one just rearms a settle timer, and the sync
+// runs once when the source has been quiet for the whole period.
+// fsnotify does not watch recursively, so every subdirectory is
+// registered up front and newly created ones are added as their create
+// events arrive.
+func watchAndSync(ctx context.Context, source, dest string, cfg *Config, settle time.Duration) error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	if err := addWatches(w, source); err != nil {
+		return err
+	}
+	log.Printf("watching %s for changes (settle %s)", source, settle)
+	timer := time.NewTimer(settle)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			if ev.Op&fsnotify.Create != 0 {
+				if fi, err := os.Stat(ev.Name); err == nil && fi.IsDir() {
+					if err := addWatches(w, ev.Name); err != nil {
+						log.Printf("watch %s: %v", ev.Name, err)
+					}
+				}
+			}
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+			timer.Reset(settle)
+		case <-timer.C:
+			resync(ctx, source, dest, cfg)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("watch: %v", err)
+		}
+	}
+}
+
+// addWatches registers dir and every directory below it.
+func addWatches(w *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		return w.Add(path)
+	})
+}
+
+// resync runs one sync pass and records the new version. Failures are
+// logged rather than fatal so a half-written source (mid-build) does
+// not kill the watcher; the next event tries again.
+func resync(ctx context.Context, source, dest string, cfg *Config) {
+	start := time.Now()
+	var stats syncStats
+	if err := syncDir(ctx, source, dest, cfg, &stats); err != nil {
+		log.Printf("resync: %v", err)
+		return
+	}
+	digest, err := sourceDigest(source, cfg.Excludes)
+	if err != nil {
+		log.Printf("resync: hash source: %v", err)
+		return
+	}
+	if err := writeVersion(dest, digest); err != nil {
+		log.Printf("resync: record version: %v", err)
+		return
+	}
+	log.Printf("resynced %d files (%d skipped, %d removed, %d bytes) in %s",
+		stats.Copied, stats.Skipped, stats.Deleted, stats.Bytes,
+		time.Since(start).Round(time.Millisecond))
+}
@@ -0,0 +1,83 @@
+//go:build !minimal
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Webhooks let deployment dashboards track installs across a fleet
+// without scraping logs: each configured URL is POSTed to when a
+// matching event fires. Delivery is best-effort — a dead dashboard
+// must never block or fail an install — so errors are only logged.
+
+// webhookClient gets a hard timeout, unlike payload downloads; a
+// notification that takes longer than this is not worth waiting for.
+var webhookClient = &http.Client{Transport: httpTransport, Timeout: 10 * time.Second}
+
+// fireWebhooks delivers event to every configured hook that subscribes
+// to it. Events are "sync_success", "sync_failure" and "child_crash".
+func fireWebhooks(cfg *Config, event, version, errMsg string) {
+	for i := range cfg.Webhooks {
+		wh := &cfg.Webhooks[i]
+		if !webhookWants(wh, event) {
+			continue
+		}
+		body := webhookPayload(wh, cfg, event, version, errMsg)
+		resp, err := webhookClient.Post(wh.URL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Printf("webhook %s: %v", wh.URL, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			log.Printf("webhook %s: status %s", wh.URL, resp.Status)
+		}
+	}
+}
+
+func webhookWants(wh *Webhook, event string) bool {
+	if len(wh.Events) == 0 {
+		return true
+	}
+	for _, e := range wh.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookPayload renders the template with {event}, {name}, {version},
+// {error}, {host} and {time} placeholders, or a default JSON object
+// when no template is configured.
+func webhookPayload(wh *Webhook, cfg *Config, event, version, errMsg string) []byte {
+	host, _ := os.Hostname()
+	now := time.Now().Format(time.RFC3339)
+	if wh.Template != "" {
+		r := strings.NewReplacer(
+			"{event}", event,
+			"{name}", cfg.Name,
+			"{version}", version,
+			"{error}", errMsg,
+			"{host}", host,
+			"{time}", now,
+		)
+		return []byte(r.Replace(wh.Template))
+	}
+	body, _ := json.Marshal(map[string]string{
+		"event":   event,
+		"name":    cfg.Name,
+		"version": version,
+		"error":   errMsg,
+		"host":    host,
+		"time":    now,
+	})
+	return body
+}
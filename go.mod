@@ -0,0 +1,15 @@
+module github.com/doudou0720/Aiwb_0Installer_Feed
+
+go 1.21
+
+require (
+	github.com/klauspost/compress v1.17.9
+	github.com/ulikunitz/xz v0.5.12
+	golang.org/x/crypto v0.17.0
+)
+
+require golang.org/x/sys v0.15.0
+
+require github.com/fsnotify/fsnotify v1.7.0
+
+require golang.org/x/sync v0.6.0
@@ -0,0 +1,80 @@
+// Package errcode classifies wrapper failures into a small, stable
+// taxonomy of machine-readable codes. Parent installers and scripts
+// branch on the code — in the log line, the status JSON or the exit
+// code — instead of parsing error prose, which is free to change.
+//
+// Codes are attached where the failure is diagnosed (errcode.Wrap)
+// and recovered anywhere in the chain with errcode.Of, which also
+// classifies common untyped causes like ENOSPC and permission errors.
+package errcode
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+)
+
+// Code is one stable failure category. The string values are part of
+// the wrapper's external contract: never renumber or rename them.
+type Code string
+
+const (
+	ConfigInvalid    Code = "E_CONFIG_INVALID"
+	DestUnwritable   Code = "E_DEST_UNWRITABLE"
+	DiskFull         Code = "E_DISK_FULL"
+	LockHeld         Code = "E_LOCK_HELD"
+	DownloadFailed   Code = "E_DOWNLOAD_FAILED"
+	VerifyFailed     Code = "E_VERIFY_FAILED"
+	SignatureInvalid Code = "E_SIGNATURE_INVALID"
+	SyncFailed       Code = "E_SYNC_FAILED"
+	EntryNotFound    Code = "E_ENTRY_NOT_FOUND"
+	LaunchFailed     Code = "E_LAUNCH_FAILED"
+	Interrupted      Code = "E_INTERRUPTED"
+	Unknown          Code = "E_UNKNOWN"
+)
+
+// Error carries a Code alongside the underlying cause. It is created
+// through Wrap and recovered with errors.As / Of.
+type Error struct {
+	Code Code
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Wrap attaches code to err; a nil err stays nil. An already-coded
+// error keeps its original, more specific code.
+func Wrap(code Code, err error) error {
+	if err == nil {
+		return nil
+	}
+	var ce *Error
+	if errors.As(err, &ce) {
+		return err
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// Of returns the code classified for err. Untyped errors are matched
+// against well-known causes; anything unrecognized is Unknown.
+func Of(err error) Code {
+	if err == nil {
+		return ""
+	}
+	var ce *Error
+	if errors.As(err, &ce) {
+		return ce.Code
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return Interrupted
+	case errors.Is(err, syscall.ENOSPC):
+		return DiskFull
+	case errors.Is(err, os.ErrPermission):
+		return DestUnwritable
+	}
+	return Unknown
+}
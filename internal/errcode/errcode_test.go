@@ -0,0 +1,42 @@
+package errcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestWrapKeepsInnerCode(t *testing.T) {
+	inner := Wrap(VerifyFailed, errors.New("checksum mismatch"))
+	outer := Wrap(SyncFailed, fmt.Errorf("sync: %w", inner))
+	if got := Of(outer); got != VerifyFailed {
+		t.Errorf("Of = %s, want %s", got, VerifyFailed)
+	}
+}
+
+func TestWrapNil(t *testing.T) {
+	if Wrap(DiskFull, nil) != nil {
+		t.Errorf("Wrap(nil) should stay nil")
+	}
+}
+
+func TestOfClassifiesUntypedCauses(t *testing.T) {
+	cases := []struct {
+		err  error
+		want Code
+	}{
+		{fmt.Errorf("copy: %w", syscall.ENOSPC), DiskFull},
+		{fmt.Errorf("mkdir: %w", os.ErrPermission), DestUnwritable},
+		{context.Canceled, Interrupted},
+		{errors.New("anything else"), Unknown},
+		{nil, ""},
+	}
+	for _, c := range cases {
+		if got := Of(c.err); got != c.want {
+			t.Errorf("Of(%v) = %s, want %s", c.err, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package errcode
+
+// The wrapper's process exit codes are a stable contract. When the
+// entry program runs, its own exit code is passed through untouched;
+// the codes below therefore only appear when the wrapper itself
+// failed (or stopped on purpose) before handing over:
+//
+//	 0  success without launching (-no-launch, up to date, ...)
+//	 1  unclassified wrapper failure
+//	 2  invalid configuration or flags (matching package flag)
+//	 3  destination not writable
+//	 4  disk full
+//	 5  another wrapper holds the sync lock
+//	 6  download failed
+//	 7  checksum verification failed
+//	 8  signature verification failed
+//	 9  sync failed after acquisition
+//	10  -check-update: an update is available
+//	11  sync succeeded but the entry program is missing
+//	12  the entry program failed to start
+//	130 interrupted (128+SIGINT)
+//
+// 11 and 12 are deliberately distinct: a parent installer retries a
+// broken payload but escalates a broken machine.
+
+// exitCodes maps each failure category to its contract exit code.
+var exitCodes = map[Code]int{
+	Unknown:          1,
+	ConfigInvalid:    2,
+	DestUnwritable:   3,
+	DiskFull:         4,
+	LockHeld:         5,
+	DownloadFailed:   6,
+	VerifyFailed:     7,
+	SignatureInvalid: 8,
+	SyncFailed:       9,
+	EntryNotFound:    11,
+	LaunchFailed:     12,
+	Interrupted:      130,
+}
+
+// ExitCode returns the contract exit code for err per its classified
+// category; nil maps to 0.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if code, ok := exitCodes[Of(err)]; ok {
+		return code
+	}
+	return 1
+}
@@ -0,0 +1,61 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExitCodeContract(t *testing.T) {
+	cases := []struct {
+		code Code
+		want int
+	}{
+		{ConfigInvalid, 2},
+		{DestUnwritable, 3},
+		{DiskFull, 4},
+		{LockHeld, 5},
+		{DownloadFailed, 6},
+		{VerifyFailed, 7},
+		{SignatureInvalid, 8},
+		{SyncFailed, 9},
+		{EntryNotFound, 11},
+		{LaunchFailed, 12},
+		{Interrupted, 130},
+		{Unknown, 1},
+	}
+	for _, c := range cases {
+		if got := ExitCode(Wrap(c.code, errors.New("x"))); got != c.want {
+			t.Errorf("ExitCode(%s) = %d, want %d", c.code, got, c.want)
+		}
+	}
+}
+
+func TestExitCodesAreDistinct(t *testing.T) {
+	seen := map[int]Code{}
+	for code, exit := range exitCodes {
+		if prev, dup := seen[exit]; dup {
+			t.Errorf("exit code %d assigned to both %s and %s", exit, prev, code)
+		}
+		seen[exit] = code
+	}
+	if _, taken := seen[10]; taken {
+		t.Errorf("exit code 10 is reserved for -check-update")
+	}
+	if _, taken := seen[0]; taken {
+		t.Errorf("exit code 0 is reserved for success")
+	}
+}
+
+func TestExitCodeUnwrapsChains(t *testing.T) {
+	err := fmt.Errorf("sync: %w", Wrap(EntryNotFound, errors.New("missing")))
+	if got := ExitCode(err); got != 11 {
+		t.Errorf("ExitCode through chain = %d, want 11", got)
+	}
+	if got := ExitCode(nil); got != 0 {
+		t.Errorf("ExitCode(nil) = %d, want 0", got)
+	}
+	if got := ExitCode(errors.New("plain")); got != 1 {
+		t.Errorf("ExitCode(plain) = %d, want 1", got)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !windows
+
+package platform
+
+import "fmt"
+
+type elevator struct{}
+
+// There is no sensible programmatic elevation on Unix; tell the user
+// to use sudo for the machine-scope sync.
+func (elevator) RelaunchElevated(extraArgs ...string) error {
+	return fmt.Errorf("machine scope needs write access to the install root; re-run the sync with sudo")
+}
@@ -0,0 +1,37 @@
+//go:build windows
+
+package platform
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+type elevator struct{}
+
+// RelaunchElevated re-runs the wrapper with the same arguments via a
+// UAC prompt (Start-Process -Verb RunAs) and waits for it, so a
+// machine-scope sync started from an unelevated shell still works.
+// The elevated child's exact exit code is not observable through
+// ShellExecute; success/failure is.
+func (elevator) RelaunchElevated(extraArgs ...string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args := append(append([]string{}, os.Args[1:]...), extraArgs...)
+	quoted := make([]string, 0, len(args))
+	for _, a := range args {
+		quoted = append(quoted, psQuote(a))
+	}
+	script := fmt.Sprintf("Start-Process -FilePath %s -ArgumentList @(%s) -Verb RunAs -Wait",
+		psQuote(exe), strings.Join(quoted, ","))
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("elevation failed or was declined: %v\n%s", err, out)
+	}
+	return nil
+}
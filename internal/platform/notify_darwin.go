@@ -0,0 +1,20 @@
+//go:build darwin
+
+package platform
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+type notifier struct{}
+
+func (notifier) Show(title, body string) error {
+	esc := func(s string) string { return strings.ReplaceAll(s, `"`, `\"`) }
+	script := fmt.Sprintf(`display notification "%s" with title "%s"`, esc(body), esc(title))
+	if out, err := exec.Command("osascript", "-e", script).CombinedOutput(); err != nil {
+		return fmt.Errorf("osascript: %v\n%s", err, out)
+	}
+	return nil
+}
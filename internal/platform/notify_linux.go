@@ -0,0 +1,20 @@
+//go:build linux
+
+package platform
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+type notifier struct{}
+
+func (notifier) Show(title, body string) error {
+	if _, err := exec.LookPath("notify-send"); err != nil {
+		return fmt.Errorf("notify-send not found")
+	}
+	if out, err := exec.Command("notify-send", title, body).CombinedOutput(); err != nil {
+		return fmt.Errorf("notify-send: %v\n%s", err, out)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !windows && !linux && !darwin
+
+package platform
+
+import "errors"
+
+type notifier struct{}
+
+func (notifier) Show(title, body string) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,29 @@
+//go:build windows
+
+package platform
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+type notifier struct{}
+
+// Toasts are raised through the WinRT notification API from
+// PowerShell, the same dependency-free route the shortcut writer uses
+// for WScript.Shell.
+func (notifier) Show(title, body string) error {
+	script := fmt.Sprintf(`
+$null = [Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType=WindowsRuntime]
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$texts = $xml.GetElementsByTagName('text')
+$null = $texts.Item(0).AppendChild($xml.CreateTextNode(%s))
+$null = $texts.Item(1).AppendChild($xml.CreateTextNode(%s))
+$toast = [Windows.UI.Notifications.ToastNotification]::new($xml)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('wrapper').Show($toast)`,
+		psQuote(title), psQuote(body))
+	if out, err := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script).CombinedOutput(); err != nil {
+		return fmt.Errorf("powershell toast: %v\n%s", err, out)
+	}
+	return nil
+}
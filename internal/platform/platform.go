@@ -0,0 +1,95 @@
+// Package platform gathers the per-OS desktop and process
+// integrations — notifications, privilege elevation, shortcuts,
+// registry provisioning, child-process containment — behind small
+// interfaces. The core never switches on runtime.GOOS: it calls
+// through the package-level implementations below, and adding Linux
+// or macOS parity for a Windows-only feature means writing one file
+// here rather than threading GOOS checks through the pipeline.
+//
+// Subsystems a platform does not support return errors.ErrUnsupported
+// so callers can downgrade the feature to a log line instead of
+// failing the sync.
+package platform
+
+import (
+	"os"
+	"os/exec"
+)
+
+// Notifier raises native desktop notifications.
+type Notifier interface {
+	// Show displays one notification with a headline and body.
+	Show(title, body string) error
+}
+
+// Elevator re-runs the wrapper with raised privileges.
+type Elevator interface {
+	// RelaunchElevated re-runs the current executable with its
+	// original arguments plus extraArgs at raised privileges and
+	// waits for it to finish.
+	RelaunchElevated(extraArgs ...string) error
+}
+
+// ShortcutSpec describes one launcher shortcut independent of the
+// OS format (.lnk, .desktop, alias) that realizes it.
+type ShortcutSpec struct {
+	Name      string // display name, without extension
+	StartMenu bool
+	Desktop   bool
+	Target    string // executable the shortcut starts
+	Args      string
+	WorkDir   string
+	Icon      string
+}
+
+// ShortcutManager writes and deletes launcher shortcuts.
+type ShortcutManager interface {
+	// Create writes the shortcut in every location the spec asks for
+	// and returns the paths it created.
+	Create(s ShortcutSpec) ([]string, error)
+	// Remove deletes what Create would have written; missing files
+	// are not an error.
+	Remove(s ShortcutSpec) error
+}
+
+// KeySpec is one registry key to provision, relative to the user
+// hive, with any template placeholders already expanded by the
+// caller.
+type KeySpec struct {
+	Path   string
+	Values map[string]any
+}
+
+// RegistryProvider provisions declarative registry keys.
+type RegistryProvider interface {
+	// Provision creates the keys and values. It returns the key
+	// paths created so far, also alongside an error, so the caller
+	// can track partially applied changes for undo.
+	Provision(keys []KeySpec) ([]string, error)
+	// Remove deletes the provisioned keys; missing keys are not an
+	// error.
+	Remove(keys []KeySpec) error
+}
+
+// ProcessManager contains a child process and its descendants so they
+// can be terminated as one unit — a job object on Windows, a process
+// group on Unix.
+type ProcessManager interface {
+	// Isolate configures cmd before it starts so its descendants
+	// land in the same unit.
+	Isolate(cmd *exec.Cmd)
+	// Adopt attaches a started child to the unit Isolate prepared.
+	Adopt(p *os.Process) error
+	// Terminate kills the child and every descendant in its unit.
+	Terminate(p *os.Process) error
+}
+
+// The build's implementations. The concrete types are defined in the
+// per-OS files; exactly one per subsystem compiles into any binary.
+var (
+	Notifications Notifier         = notifier{}
+	Elevation     Elevator         = elevator{}
+	Shortcuts     ShortcutManager  = shortcutManager{}
+	Registry      RegistryProvider = registryProvider{}
+	Processes     ProcessManager   = newProcessManager()
+)
@@ -0,0 +1,35 @@
+//go:build !windows
+
+package platform
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// On Unix the containment unit is a process group: the child starts
+// as a group leader and termination signals the whole group, so
+// helper processes the entry program spawns do not outlive it.
+
+type processManager struct{}
+
+func newProcessManager() processManager { return processManager{} }
+
+func (processManager) Isolate(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+func (processManager) Adopt(p *os.Process) error { return nil }
+
+func (processManager) Terminate(p *os.Process) error {
+	// Negative pid signals the process group. Fall back to the
+	// single process if the child was not started through Isolate.
+	if err := syscall.Kill(-p.Pid, syscall.SIGKILL); err != nil {
+		return p.Kill()
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+//go:build windows
+
+package platform
+
+import (
+	"os"
+	"os/exec"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// On Windows the containment unit is a job object with
+// KILL_ON_JOB_CLOSE: descendants of the entry program land in the
+// same job, TerminateJobObject kills them all at once, and if the
+// wrapper itself dies the closing job handle takes the tree down
+// with it.
+
+type processManager struct {
+	mu   sync.Mutex
+	jobs map[int]windows.Handle
+}
+
+func newProcessManager() *processManager {
+	return &processManager{jobs: map[int]windows.Handle{}}
+}
+
+func (*processManager) Isolate(cmd *exec.Cmd) {}
+
+func (m *processManager) Adopt(p *os.Process) error {
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		return err
+	}
+	info := windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION{
+		BasicLimitInformation: windows.JOBOBJECT_BASIC_LIMIT_INFORMATION{
+			LimitFlags: windows.JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE,
+		},
+	}
+	if _, err := windows.SetInformationJobObject(job, windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info))); err != nil {
+		windows.CloseHandle(job)
+		return err
+	}
+	h, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(p.Pid))
+	if err != nil {
+		windows.CloseHandle(job)
+		return err
+	}
+	err = windows.AssignProcessToJobObject(job, h)
+	windows.CloseHandle(h)
+	if err != nil {
+		windows.CloseHandle(job)
+		return err
+	}
+	m.mu.Lock()
+	m.jobs[p.Pid] = job
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *processManager) Terminate(p *os.Process) error {
+	m.mu.Lock()
+	job, ok := m.jobs[p.Pid]
+	delete(m.jobs, p.Pid)
+	m.mu.Unlock()
+	if !ok {
+		return p.Kill()
+	}
+	err := windows.TerminateJobObject(job, 1)
+	windows.CloseHandle(job)
+	if err != nil {
+		return p.Kill()
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build !windows || minimal
+
+package platform
+
+import "errors"
+
+// Registry provisioning only exists on Windows.
+
+type registryProvider struct{}
+
+func (registryProvider) Provision(keys []KeySpec) ([]string, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (registryProvider) Remove(keys []KeySpec) error { return nil }
@@ -0,0 +1,86 @@
+//go:build windows && !minimal
+
+package platform
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// Declarative registry provisioning. Only HKCU is supported: the
+// wrapper runs unelevated and machine-wide keys belong to a real
+// installer.
+
+type registryProvider struct{}
+
+func (registryProvider) Provision(keys []KeySpec) ([]string, error) {
+	var created []string
+	for _, ks := range keys {
+		path, err := cleanRegPath(ks.Path)
+		if err != nil {
+			return created, err
+		}
+		k, _, err := registry.CreateKey(registry.CURRENT_USER, path, registry.ALL_ACCESS)
+		if err != nil {
+			return created, fmt.Errorf("create HKCU\\%s: %w", path, err)
+		}
+		for name, val := range ks.Values {
+			switch v := val.(type) {
+			case string:
+				err = k.SetStringValue(name, v)
+			case float64:
+				err = k.SetDWordValue(name, uint32(v))
+			case bool:
+				var d uint32
+				if v {
+					d = 1
+				}
+				err = k.SetDWordValue(name, d)
+			default:
+				err = fmt.Errorf("unsupported value type %T", val)
+			}
+			if err != nil {
+				k.Close()
+				return created, fmt.Errorf("set HKCU\\%s!%s: %w", path, name, err)
+			}
+		}
+		k.Close()
+		created = append(created, path)
+	}
+	return created, nil
+}
+
+func (registryProvider) Remove(keys []KeySpec) error {
+	for _, ks := range keys {
+		path, err := cleanRegPath(ks.Path)
+		if err != nil {
+			continue
+		}
+		if err := registry.DeleteKey(registry.CURRENT_USER, path); err != nil && err != registry.ErrNotExist {
+			return fmt.Errorf("remove HKCU\\%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// cleanRegPath normalizes and bounds a configured key path: it must
+// be relative (to HKCU), non-empty, and must not start with another
+// hive name or reach into Classes (associations own that namespace).
+func cleanRegPath(p string) (string, error) {
+	p = strings.Trim(strings.ReplaceAll(p, "/", `\`), `\`)
+	if p == "" {
+		return "", fmt.Errorf("empty registry path")
+	}
+	upper := strings.ToUpper(p)
+	for _, hive := range []string{"HKEY_", "HKCU", "HKLM", "HKCR"} {
+		if strings.HasPrefix(upper, hive) {
+			return "", fmt.Errorf("registry path %q: paths are relative to HKCU, drop the hive prefix", p)
+		}
+	}
+	if strings.Contains(p, "..") {
+		return "", fmt.Errorf("registry path %q: '..' not allowed", p)
+	}
+	return p, nil
+}
@@ -0,0 +1,16 @@
+//go:build !windows || minimal
+
+package platform
+
+import "errors"
+
+// Shortcut creation is a Windows integration for now; the spec is
+// accepted but unsupported elsewhere.
+
+type shortcutManager struct{}
+
+func (shortcutManager) Create(s ShortcutSpec) ([]string, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (shortcutManager) Remove(s ShortcutSpec) error { return nil }
@@ -0,0 +1,86 @@
+//go:build windows && !minimal
+
+package platform
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Shortcuts are written through the WScript.Shell COM object via
+// PowerShell: it is present on every supported Windows and spares us
+// a hand-rolled .lnk serializer.
+
+type shortcutManager struct{}
+
+// shortcutDirs returns the directories that should hold a shortcut
+// per the spec.
+func shortcutDirs(s ShortcutSpec) ([]string, error) {
+	var dirs []string
+	if s.StartMenu {
+		appData := os.Getenv("APPDATA")
+		if appData == "" {
+			return nil, fmt.Errorf("APPDATA not set")
+		}
+		dirs = append(dirs, filepath.Join(appData, "Microsoft", "Windows", "Start Menu", "Programs"))
+	}
+	if s.Desktop {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, filepath.Join(home, "Desktop"))
+	}
+	return dirs, nil
+}
+
+func (shortcutManager) Create(s ShortcutSpec) ([]string, error) {
+	dirs, err := shortcutDirs(s)
+	if err != nil {
+		return nil, err
+	}
+	var created []string
+	for _, dir := range dirs {
+		link := filepath.Join(dir, s.Name+".lnk")
+		if err := writeLnk(link, s.Target, s.Args, s.WorkDir, s.Icon); err != nil {
+			return created, fmt.Errorf("create shortcut %s: %w", link, err)
+		}
+		created = append(created, link)
+	}
+	return created, nil
+}
+
+func (shortcutManager) Remove(s ShortcutSpec) error {
+	dirs, err := shortcutDirs(s)
+	if err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		link := filepath.Join(dir, s.Name+".lnk")
+		if err := os.Remove(link); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeLnk creates a .lnk through WScript.Shell.
+func writeLnk(link, target, args, workDir, icon string) error {
+	script := fmt.Sprintf(
+		`$ws = New-Object -ComObject WScript.Shell; `+
+			`$s = $ws.CreateShortcut(%s); `+
+			`$s.TargetPath = %s; `+
+			`$s.Arguments = %s; `+
+			`$s.WorkingDirectory = %s; `+
+			`$s.IconLocation = %s; `+
+			`$s.Save()`,
+		psQuote(link), psQuote(target), psQuote(args), psQuote(workDir), psQuote(icon))
+	cmd := exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", script)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("powershell: %v\n%s", err, out)
+	}
+	return nil
+}
@@ -0,0 +1,578 @@
+// Package config defines the wrapper's configuration schema
+// (wrapper.config.json) and its loading and path-resolution rules, so
+// programs embedding the sync engine construct the same Config the
+// binary does.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Config mirrors wrapper.config.json, which is looked up next to the
+// wrapper executable. Command-line flags override any value set here.
+type Config struct {
+	// Source is the payload to install from: a local directory
+	// (typically the 0install implementation cache).
+	Source string `json:"source"`
+
+	// Mirrors are alternative URLs for a URL source, tried in order
+	// of measured responsiveness when the primary is slow or blocked.
+	Mirrors []string `json:"mirrors"`
+
+	// SHA256 is the expected hex digest of a downloaded payload.
+	// When empty, a .sha256 sidecar next to the source URL is tried.
+	SHA256 string `json:"sha256"`
+
+	// MinisignKeys pins minisign/ed25519 public keys (the base64
+	// line of a minisign .pub file). When any key is pinned, a valid
+	// detached .minisig signature becomes mandatory for downloads.
+	MinisignKeys []string `json:"minisign_keys"`
+
+	// Feed is where update checks look for the latest release: a
+	// feed JSON URL or "github:owner/repo".
+	Feed string `json:"feed"`
+
+	// SevenZip configures the external 7-Zip binary used for .7z
+	// payloads: an explicit path and an optional pinned SHA-256.
+	SevenZip struct {
+		Path   string `json:"path"`
+		SHA256 string `json:"sha256"`
+	} `json:"sevenzip"`
+
+	// Proxy is an explicit proxy URL (http, https or socks5, basic
+	// auth allowed) for all network traffic. Empty means honor the
+	// HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment.
+	Proxy string `json:"proxy"`
+
+	// Dest is where the payload is synced to. Relative paths are
+	// resolved against the user's local app-data directory. Empty
+	// means <local-app-data>/<Name>.
+	Dest string `json:"dest"`
+
+	// Name identifies this install and is used for the default dest.
+	Name string `json:"name"`
+
+	// Scope is "user" (default) or "machine". Machine scope targets
+	// a machine-wide default dest (ProgramData on Windows, /opt
+	// elsewhere) with a shared version file; syncing there needs
+	// elevation, launching does not.
+	Scope string `json:"scope"`
+
+	// Entry is the program to launch after a successful sync,
+	// relative to dest.
+	Entry string `json:"entry"`
+
+	// Args are extra arguments passed to the entry program.
+	Args []string `json:"args"`
+
+	// Env restricts the environment the entry program inherits; see
+	// EnvConfig. An empty value passes the full environment through.
+	Env EnvConfig `json:"env"`
+
+	// Entries, when non-empty, launches a small dependency-ordered
+	// group of programs instead of Entry: each starts after the ones
+	// it lists in "after" are ready, the group is supervised
+	// together, and shutdown happens in reverse start order. Entry
+	// still names the program OS integrations point at.
+	Entries []EntryConfig `json:"entries"`
+
+	// Prereqs lists runtimes the payload needs (see PrereqConfig);
+	// the wrapper verifies them before launching.
+	Prereqs []PrereqConfig `json:"prereqs"`
+
+	// EnvFile is a KEY=value file loaded into the child environment
+	// at launch, relative to dest unless absolute. Empty means
+	// dest/.env when that file exists. Values may use the
+	// {dest}/{name}/{entry}/{version} placeholders.
+	EnvFile string `json:"env_file"`
+
+	// MinOSVersion is the lowest OS version the payload supports:
+	// major.minor.build on Windows, the product version on macOS and
+	// the kernel release on Linux. Checked before syncing.
+	MinOSVersion string `json:"min_os_version"`
+
+	// RequireAdmin refuses to run without administrative rights
+	// (an elevated token on Windows, root elsewhere).
+	RequireAdmin bool `json:"require_admin"`
+
+	// Mode is "copy" (default; only add/overwrite) or "mirror"
+	// (also delete files in dest that the source does not have).
+	Mode string `json:"mode"`
+
+	// Excludes are path prefixes (relative to source, slash-separated)
+	// that are never synced.
+	Excludes []string `json:"excludes"`
+
+	// Overlays are extra local source directories merged over Source
+	// in order (base, then each overlay; later layers win), so a patch
+	// or branding layer can sit on top of the upstream payload.
+	Overlays []string `json:"overlays"`
+
+	// Protected are dest-relative paths holding app-generated user
+	// data ("saves/", "config/", "*.ini") that sync never overwrites
+	// and mirror mode and uninstall never delete. Directory prefixes
+	// and base-name globs are both accepted.
+	Protected []string `json:"protected"`
+
+	// Shortcuts controls Start Menu / Desktop shortcut creation on
+	// Windows. The shortcut points at the wrapper itself so every
+	// relaunch goes through sync.
+	Shortcuts ShortcutConfig `json:"shortcuts"`
+
+	// FileAssociations lists file types the entry program handles.
+	// The wrapper registers them (HKCU ProgIDs on Windows, a .desktop
+	// entry plus xdg-mime on Linux) pointing at itself, so opening an
+	// associated file syncs first and then forwards the path to the
+	// entry. Removed again at uninstall.
+	FileAssociations []FileAssoc `json:"file_associations"`
+
+	// Protocols lists URL schemes (e.g. "aiwb" for aiwb:// links)
+	// routed through the wrapper: sync if needed, then launch the
+	// entry with the URL as an argument. Removed at uninstall.
+	Protocols []string `json:"protocols"`
+
+	// AppsEntry, when enabled, writes the HKCU uninstall keys so the
+	// install shows up in Windows Settings / Add-Remove Programs with
+	// an uninstall action that runs the wrapper's -uninstall mode.
+	AppsEntry struct {
+		Enabled        bool   `json:"enabled"`
+		DisplayName    string `json:"display_name"`
+		DisplayVersion string `json:"display_version"`
+		Publisher      string `json:"publisher"`
+	} `json:"apps_entry"`
+
+	// RegistryKeys declares HKCU keys/values created at install and
+	// deleted at uninstall, for payloads that need a little registry
+	// state without shipping a full installer. Paths are relative to
+	// HKEY_CURRENT_USER; string values may reference {dest}, {name},
+	// {entry} and {version}. JSON numbers become DWORDs.
+	RegistryKeys []RegistryKey `json:"registry_keys"`
+
+	// ContextMenu registers an "Open with <App>" entry in the file
+	// manager (HKCU shell verb on all files on Windows, a Nautilus
+	// script on Linux) that routes the selection through the
+	// wrapper's launch path. Removed at uninstall.
+	ContextMenu struct {
+		Enabled bool `json:"enabled"`
+
+		// Label defaults to "Open with <Name>".
+		Label string `json:"label"`
+	} `json:"context_menu"`
+
+	// MacAppBundle generates a minimal <Name>.app in ~/Applications
+	// whose executable is the wrapper, so the synced app shows up in
+	// Launchpad/Spotlight and behaves properly in the Dock. Ignored
+	// off macOS; removed at uninstall.
+	MacAppBundle bool `json:"mac_app_bundle"`
+
+	// AddToPath is a dest-relative directory (e.g. "bin") added to
+	// the user's PATH for CLI payloads: HKCU\Environment plus a
+	// settings-change broadcast on Windows, a marked block in
+	// ~/.profile on Unix. Removed cleanly at uninstall.
+	AddToPath string `json:"add_to_path"`
+
+	// FirewallRules declares inbound Windows Firewall rules the
+	// entry needs, created at install (elevating if necessary) and
+	// deleted at uninstall.
+	FirewallRules []FirewallRule `json:"firewall_rules"`
+
+	// Autostart registers the wrapper (sync-and-launch) to run at
+	// login: HKCU Run key on Windows, an autostart .desktop entry on
+	// Linux, a LaunchAgent on macOS. Cleaned up at uninstall.
+	Autostart bool `json:"autostart"`
+
+	// DataDir manages a writable per-app data directory separate from
+	// the synced program tree, exported to the entry as
+	// WRAPPER_DATA_DIR. "auto" picks the platform data home for Name;
+	// other values are explicit (dest-relative when not absolute, and
+	// then automatically protected). Empty disables it.
+	DataDir string `json:"data_dir"`
+
+	// Migrations are once-only upgrade actions keyed by from/to
+	// version (digest prefix) edges, recorded in the dest state file.
+	Migrations []Migration `json:"migrations"`
+
+	// FirstRun lists setup actions run once, the first time this dest
+	// is launched. The entry also sees WRAPPER_FIRST_RUN=1 then.
+	FirstRun []SetupAction `json:"first_run"`
+
+	// SharedCache syncs each version once into a machine-wide cache
+	// (under the machine install root) and hardlinks per-user dests
+	// into it, so multi-user machines store one copy per version.
+	SharedCache bool `json:"shared_cache"`
+
+	// Prefetch stages the next feed version in the background after
+	// launch, so the following launch switches to it instantly.
+	Prefetch bool `json:"prefetch"`
+
+	// RestartOnUpdate makes daemon mode kill and relaunch the entry
+	// program after a periodic sync that changed dest.
+	RestartOnUpdate bool `json:"restart_on_update"`
+
+	// Notifications shows a native desktop notification when a sync
+	// updates dest or fails.
+	Notifications bool `json:"notifications"`
+
+	// Webhooks are POSTed to on sync and child-crash events so
+	// dashboards can track installs without log scraping.
+	Webhooks []Webhook `json:"webhooks"`
+
+	// Plugins are external executables invoked at hook points
+	// (pre_sync, post_sync, pre_launch, on_error) with a JSON request
+	// on stdin, so custom steps can ride along without forking the
+	// wrapper.
+	Plugins []Plugin `json:"plugins"`
+
+	// Force re-copies every file even if size and mtime match.
+	Force bool `json:"force"`
+
+	// PowerPolicy adjusts syncing on battery power: "throttle" drops
+	// the copy pool to one worker, "defer" additionally skips
+	// background syncs until AC power returns. Empty or "none"
+	// ignores the power state.
+	PowerPolicy string `json:"power_policy"`
+
+	// AVRetrySec extends the per-file retry window (seconds, default
+	// 2) for copies that fail with scanner-style sharing violations.
+	AVRetrySec int `json:"av_retry_sec"`
+
+	// CrashReportURL, when set, opts in to uploading wrapper crash
+	// reports to this endpoint. Reports are always written to
+	// dest/.wrapper-crashes regardless.
+	CrashReportURL string `json:"crash_report_url"`
+
+	// TelemetryURL, when set, opts in to POSTing one anonymous
+	// aggregate event per sync attempt (duration, size bucket,
+	// failure code, OS version) to this endpoint.
+	TelemetryURL string `json:"telemetry_url"`
+
+	// Tray shows a status icon with quick actions while the wrapper
+	// runs in daemon mode (Windows only).
+	Tray bool `json:"tray"`
+
+	// Manifest names a file in the source tree listing the payload
+	// paths the entry actually needs (one per line, # comments,
+	// protected-style globs); only those are synced. The entry
+	// programs are always included.
+	Manifest string `json:"manifest"`
+
+	// Full ignores the install manifest and syncs the complete
+	// payload.
+	Full bool `json:"full"`
+
+	// Paths maps globs to per-path sync policies: "copy", "link",
+	// "skip", "protect" or "mirror". Patterns match like protected
+	// entries and the longest match wins, so one section describes
+	// how every part of the payload is materialized.
+	Paths map[string]string `json:"paths"`
+
+	// LinkDirs lists source subtrees (e.g. "assets") served straight
+	// out of the source through a directory junction on Windows or a
+	// symlink elsewhere, instead of being copied — multi-GB static
+	// data never gets duplicated. Meant for stable local sources.
+	LinkDirs []string `json:"link_dirs"`
+
+	// DestSymlinks says what to do with symlinks in the payload:
+	// "preserve" recreates them, "materialize" dereferences them into
+	// real copies, "forbid" rejects them. Empty picks the per-OS
+	// default — preserve on Unix, materialize on Windows, where
+	// creating symlinks usually needs a privilege users lack.
+	DestSymlinks string `json:"dest_symlinks"`
+
+	// DetectRenames reuses identical large files already in dest when
+	// a new version merely moved or renamed them, hardlinking the old
+	// content into place instead of copying it again from the source.
+	DetectRenames bool `json:"detect_renames"`
+
+	// Dedup hardlinks byte-identical files within dest after each
+	// sync (bundled runtimes duplicated across plugins), reporting
+	// the space saved.
+	Dedup bool `json:"dedup"`
+
+	// CompressDest enables NTFS compression on dest after each sync
+	// (via compact.exe); payloads compress well and many users
+	// install to small system SSDs. Ignored outside Windows.
+	CompressDest bool `json:"compress_dest"`
+
+	// Wait keeps the wrapper alive until the entry program exits and
+	// propagates its exit code.
+	Wait bool `json:"wait"`
+
+	// TeeOutput interleaves the entry program's stdout/stderr on the
+	// wrapper's console with [name]/[name:err] prefixes while also
+	// writing the raw streams to dest/.wrapper-logs. Wait mode only.
+	TeeOutput bool `json:"tee_output"`
+}
+
+// EntryConfig is one program in the "entries" launch group.
+type EntryConfig struct {
+	// Name identifies the entry in logs and in "after" references.
+	Name string `json:"name"`
+
+	// Path is the program to run, relative to dest.
+	Path string `json:"path"`
+
+	// Args are extra arguments for this entry.
+	Args []string `json:"args"`
+
+	// After lists entries that must be ready before this one starts.
+	After []string `json:"after"`
+
+	// Ready is an optional readiness probe; without one the entry
+	// counts as ready as soon as it started.
+	Ready *ReadyProbe `json:"ready"`
+
+	// When restricts the entry to matching machines; without one the
+	// entry always runs.
+	When *EntryCondition `json:"when"`
+}
+
+// PrereqConfig declares one runtime the payload needs before it can
+// launch. Types that have no meaning on the current OS are skipped.
+type PrereqConfig struct {
+	// Type is one of "vcredist", "dotnet", "webview2" or "glibc".
+	Type string `json:"type"`
+
+	// MinVersion is the lowest acceptable version, e.g. "14.38" or
+	// "8.0".
+	MinVersion string `json:"min_version"`
+
+	// Installer, when set, is a program in the payload (relative to
+	// dest) run once to satisfy the prerequisite when the check
+	// fails.
+	Installer string `json:"installer"`
+}
+
+// EntryCondition selects which machines an entry applies to, letting
+// one payload and config serve several platforms and hardware tiers.
+// All set fields must hold.
+type EntryCondition struct {
+	// OS must equal runtime.GOOS (e.g. "windows", "linux").
+	OS string `json:"os"`
+
+	// Arch must equal runtime.GOARCH (e.g. "amd64", "arm64").
+	Arch string `json:"arch"`
+
+	// GPU, when set, requires (true) or forbids (false) a GPU.
+	GPU *bool `json:"gpu"`
+
+	// MinWindowsBuild requires at least this Windows build number;
+	// it never matches off Windows.
+	MinWindowsBuild int `json:"min_windows_build"`
+}
+
+// ReadyProbe describes how to tell that an entry finished starting.
+type ReadyProbe struct {
+	// TCP is a host:port that must accept a connection.
+	TCP string `json:"tcp"`
+
+	// URL is an HTTP URL that must answer with a status below 400.
+	URL string `json:"url"`
+
+	// TimeoutSec bounds the probe; default 30 seconds.
+	TimeoutSec int `json:"timeout_sec"`
+}
+
+// EnvConfig filters the environment inherited by the entry program,
+// so proxies, tokens or a conflicting PYTHONPATH from the parent
+// shell do not leak into the app. Names match case-insensitively and
+// may end in * for a prefix match. Entries the wrapper itself adds
+// (WRAPPER_FIRST_RUN, plugin env) always pass.
+type EnvConfig struct {
+	// Allow, when non-empty, drops every inherited variable whose
+	// name matches none of these patterns.
+	Allow []string `json:"allow"`
+
+	// Deny drops inherited variables matching these patterns; it is
+	// applied after Allow.
+	Deny []string `json:"deny"`
+}
+
+// ShortcutConfig declares which shortcuts to create at install and
+// remove at uninstall.
+type ShortcutConfig struct {
+	StartMenu bool `json:"start_menu"`
+	Desktop   bool `json:"desktop"`
+
+	// DisplayName is the shortcut file name; defaults to Name.
+	DisplayName string `json:"display_name"`
+
+	// Icon is a path relative to dest used as the shortcut icon;
+	// empty uses the entry program's own icon.
+	Icon string `json:"icon"`
+
+	// Args are extra arguments baked into the shortcut target.
+	Args []string `json:"args"`
+}
+
+// FileAssoc describes one file association.
+type FileAssoc struct {
+	// Extension including the leading dot, e.g. ".aiwb".
+	Extension string `json:"extension"`
+
+	// ProgID defaults to "<Name><Extension-without-dot>".
+	ProgID string `json:"progid"`
+
+	// Description shows up in Explorer's type column.
+	Description string `json:"description"`
+
+	// Icon is a dest-relative path; empty uses the entry program.
+	Icon string `json:"icon"`
+
+	// MimeType is required for registration on Linux (xdg-mime).
+	MimeType string `json:"mime_type"`
+}
+
+// Migration is one once-only upgrade action.
+type Migration struct {
+	// ID names the migration in the state file; defaults to its
+	// position and edge.
+	ID string `json:"id"`
+
+	// From and To are digest prefixes selecting the upgrade edge;
+	// empty matches any version.
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	// Run is a dest-relative program plus arguments executed in dest
+	// with WRAPPER_DEST set.
+	Run []string `json:"run"`
+
+	// Action is a built-in alternative to Run: "delete" or "mkdir",
+	// applied to the dest-relative Path.
+	Action string `json:"action"`
+	Path   string `json:"path"`
+}
+
+// SetupAction is one first-run step: a dest-relative script with
+// arguments, or a built-in "delete"/"mkdir" on Path.
+type SetupAction struct {
+	Run    []string `json:"run"`
+	Action string   `json:"action"`
+	Path   string   `json:"path"`
+}
+
+// Webhook is one notification endpoint.
+type Webhook struct {
+	URL string `json:"url"`
+
+	// Events filters which events fire this hook: any of
+	// "sync_success", "sync_failure", "child_crash". Empty means all.
+	Events []string `json:"events"`
+
+	// Template overrides the default JSON payload. {event}, {name},
+	// {version}, {error}, {host} and {time} are substituted.
+	Template string `json:"template"`
+}
+
+// Plugin is one external hook executable.
+type Plugin struct {
+	// Path to the executable; relative paths resolve against dest.
+	Path string `json:"path"`
+
+	// Hooks selects when it runs: any of "pre_sync", "post_sync",
+	// "pre_launch", "on_error". Empty means every hook.
+	Hooks []string `json:"hooks"`
+}
+
+// FirewallRule is one inbound allow rule for the entry program.
+type FirewallRule struct {
+	// Name defaults to "<install name> <protocol> <port>".
+	Name string `json:"name"`
+
+	// Protocol is "tcp" or "udp".
+	Protocol string `json:"protocol"`
+
+	// Port is the local port to allow.
+	Port int `json:"port"`
+}
+
+// RegistryKey is one declaratively provisioned HKCU key.
+type RegistryKey struct {
+	// Path under HKEY_CURRENT_USER, e.g. "Software\\Aiwb".
+	Path string `json:"path"`
+
+	// Values to set on the key; "" is the default value.
+	Values map[string]any `json:"values"`
+}
+
+// FileName is the config file looked up next to the executable.
+const FileName = "wrapper.config.json"
+
+// Load reads wrapper.config.json from the wrapper's own
+// directory. A missing file is not an error: everything can be given
+// on the command line.
+func Load() (*Config, error) {
+	cfg, _, err := LoadFile()
+	return cfg, err
+}
+
+// LoadFile is Load but also reports whether a config file was
+// actually found, so the binary can fall back to an embedded default
+// only when nothing external overrides it.
+func LoadFile() (*Config, bool, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return &Config{}, false, nil
+	}
+	path := filepath.Join(filepath.Dir(exe), FileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Config{}, false, nil
+		}
+		return nil, false, fmt.Errorf("read %s: %w", path, err)
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, true, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, true, nil
+}
+
+// Parse decodes a config document, as used for both the on-disk file
+// and build-time embedded defaults.
+func Parse(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ResolveDest turns the configured dest into an absolute path,
+// defaulting to a per-user directory named after the install.
+func (c *Config) ResolveDest() (string, error) {
+	dest := c.Dest
+	if dest == "" {
+		if c.Name == "" {
+			return "", fmt.Errorf("neither -dest nor -name given")
+		}
+		if c.Scope == "machine" {
+			dest = filepath.Join(MachineInstallRoot(), c.Name)
+		} else {
+			base, err := os.UserCacheDir()
+			if err != nil {
+				return "", err
+			}
+			dest = filepath.Join(base, c.Name)
+		}
+	}
+	return filepath.Abs(dest)
+}
+
+// MachineInstallRoot is the default parent directory for -scope=machine
+// installs.
+func MachineInstallRoot() string {
+	if runtime.GOOS == "windows" {
+		if pd := os.Getenv("ProgramData"); pd != "" {
+			return pd
+		}
+		return `C:\ProgramData`
+	}
+	return "/opt"
+}
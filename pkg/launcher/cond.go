@@ -0,0 +1,63 @@
+package launcher
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+)
+
+// Conditional entries let one payload and one config serve several
+// platforms and hardware tiers: each entry may carry a "when" clause
+// (os, arch, gpu, minimum Windows build) that is evaluated on the
+// running machine at launch time.
+
+// entryApplies reports whether e's condition holds here; an entry
+// without one always applies.
+func entryApplies(e config.EntryConfig) bool {
+	c := e.When
+	if c == nil {
+		return true
+	}
+	if c.OS != "" && !strings.EqualFold(c.OS, runtime.GOOS) {
+		return false
+	}
+	if c.Arch != "" && !strings.EqualFold(c.Arch, runtime.GOARCH) {
+		return false
+	}
+	if c.GPU != nil && *c.GPU != hasGPU() {
+		return false
+	}
+	if c.MinWindowsBuild > 0 && windowsBuild() < c.MinWindowsBuild {
+		return false
+	}
+	return true
+}
+
+// selectEntries drops entries whose condition fails and prunes
+// "after" edges that pointed at a dropped entry, so a UI that waits
+// on an optional GPU service still starts when the service is not
+// selected.
+func selectEntries(entries []config.EntryConfig) []config.EntryConfig {
+	dropped := make(map[string]bool)
+	out := entries[:0:0]
+	for _, e := range entries {
+		if entryApplies(e) {
+			out = append(out, e)
+		} else {
+			dropped[e.Name] = true
+		}
+	}
+	// Names that never existed stay in place so entryOrder still
+	// rejects them.
+	for i, e := range out {
+		after := e.After[:0:0]
+		for _, dep := range e.After {
+			if !dropped[dep] {
+				after = append(after, dep)
+			}
+		}
+		out[i].After = after
+	}
+	return out
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package launcher
+
+import (
+	"path/filepath"
+	"runtime"
+)
+
+// hasGPU reports whether the machine has a usable GPU. Every Mac
+// ships one; elsewhere a DRI device node is the cheap portable
+// signal.
+func hasGPU() bool {
+	if runtime.GOOS == "darwin" {
+		return true
+	}
+	matches, _ := filepath.Glob("/dev/dri/card*")
+	return len(matches) > 0
+}
+
+// windowsBuild is zero off Windows, so min_windows_build conditions
+// never pass here.
+func windowsBuild() int { return 0 }
@@ -0,0 +1,43 @@
+package launcher
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+)
+
+func TestEntryApplies(t *testing.T) {
+	if !entryApplies(config.EntryConfig{Name: "a"}) {
+		t.Error("entry without a condition must apply")
+	}
+	if !entryApplies(config.EntryConfig{Name: "a", When: &config.EntryCondition{OS: runtime.GOOS, Arch: runtime.GOARCH}}) {
+		t.Error("matching os/arch must apply")
+	}
+	if entryApplies(config.EntryConfig{Name: "a", When: &config.EntryCondition{OS: "plan9"}}) {
+		t.Error("foreign os must not apply")
+	}
+	if entryApplies(config.EntryConfig{Name: "a", When: &config.EntryCondition{Arch: "wasm"}}) {
+		t.Error("foreign arch must not apply")
+	}
+}
+
+func TestSelectEntriesPrunesDroppedDeps(t *testing.T) {
+	entries := []config.EntryConfig{
+		{Name: "gpu-svc", When: &config.EntryCondition{OS: "plan9"}},
+		{Name: "ui", After: []string{"gpu-svc"}},
+	}
+	out := selectEntries(entries)
+	if len(out) != 1 || out[0].Name != "ui" {
+		t.Fatalf("selectEntries = %+v", out)
+	}
+	if len(out[0].After) != 0 {
+		t.Fatalf("edge to dropped entry not pruned: %v", out[0].After)
+	}
+	// A reference that never existed must survive for entryOrder to
+	// reject.
+	out = selectEntries([]config.EntryConfig{{Name: "ui", After: []string{"ghost"}}})
+	if len(out[0].After) != 1 {
+		t.Fatal("unknown dependency pruned")
+	}
+}
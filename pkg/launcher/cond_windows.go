@@ -0,0 +1,41 @@
+//go:build windows
+
+package launcher
+
+import (
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+)
+
+// displayClassKey is the device-class registry key under which every
+// installed display adapter gets a numbered subkey.
+const displayClassKey = `SYSTEM\CurrentControlSet\Control\Class\{4d36e968-e325-11ce-bfc1-08002be10318}`
+
+// hasGPU reports whether a display adapter driver is installed.
+func hasGPU() bool {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, displayClassKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return false
+	}
+	defer k.Close()
+	names, err := k.ReadSubKeyNames(0)
+	if err != nil {
+		return false
+	}
+	for _, n := range names {
+		// Adapter instances are numbered (0000, 0001, ...);
+		// the class key also holds a "Properties" subkey.
+		if len(n) == 4 && n[0] >= '0' && n[0] <= '9' {
+			return true
+		}
+	}
+	return false
+}
+
+// windowsBuild returns the OS build number (e.g. 22000 for the first
+// Windows 11 release). RtlGetVersion is unaffected by manifest-based
+// version lying, unlike GetVersionEx.
+func windowsBuild() int {
+	info := windows.RtlGetVersion()
+	return int(info.BuildNumber)
+}
@@ -0,0 +1,117 @@
+package launcher
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// When the entry program dies from a signal or an unhandled
+// exception — as opposed to exiting with an ordinary error code — a
+// crash record is dropped into dest/.wrapper-crashes so the failure
+// can be analyzed after the console scrolled away. The record holds
+// the exit code, the decoded signal or NTSTATUS, and the last lines
+// of output when the wrapper was capturing them (wait mode). Full
+// minidumps are out of scope here: machines that need them can
+// enable WER LocalDumps or a core pattern through the registry_keys
+// or first_run config.
+
+// crashDirName holds crash records under dest; the metadata prefix
+// keeps mirror mode away from it.
+const crashDirName = ".wrapper-crashes"
+
+// crashTailLines bounds the output tail kept for a crash record.
+const crashTailLines = 50
+
+// CrashRecord is the JSON document written for one crash.
+type CrashRecord struct {
+	Time       time.Time `json:"time"`
+	Entry      string    `json:"entry"`
+	ExitCode   int       `json:"exit_code"`
+	Signal     string    `json:"signal,omitempty"`
+	OutputTail []string  `json:"output_tail,omitempty"`
+}
+
+// tailBuffer is an io.Writer remembering the last max lines written
+// through it. It is safe for concurrent use by the stdout and stderr
+// pipelines.
+type tailBuffer struct {
+	mu      sync.Mutex
+	max     int
+	lines   []string
+	partial []byte
+}
+
+func newTailBuffer(max int) *tailBuffer {
+	return &tailBuffer{max: max}
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, b := range p {
+		if b == '\n' {
+			t.appendLine(string(t.partial))
+			t.partial = t.partial[:0]
+			continue
+		}
+		t.partial = append(t.partial, b)
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) appendLine(line string) {
+	t.lines = append(t.lines, line)
+	if len(t.lines) > t.max {
+		t.lines = t.lines[len(t.lines)-t.max:]
+	}
+}
+
+// Lines returns the remembered tail, including a trailing partial
+// line the child never terminated.
+func (t *tailBuffer) Lines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := append([]string(nil), t.lines...)
+	if len(t.partial) > 0 {
+		out = append(out, string(t.partial))
+	}
+	return out
+}
+
+// RecordCrash writes a crash record when err is a crash — a signal
+// death or an unhandled exception — and returns the record's path.
+// An ordinary non-zero exit writes nothing and returns "".
+func RecordCrash(dest, entry string, err error, tail []string) (string, error) {
+	ee, ok := err.(*exec.ExitError)
+	if !ok {
+		return "", nil
+	}
+	sig, crashed := crashSignal(ee)
+	if !crashed {
+		return "", nil
+	}
+	dir := filepath.Join(dest, crashDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	rec := CrashRecord{
+		Time:       time.Now(),
+		Entry:      entry,
+		ExitCode:   ee.ExitCode(),
+		Signal:     sig,
+		OutputTail: tail,
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "crash-"+rec.Time.Format("20060102-150405")+".json")
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+
+package launcher
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// crashSignal reports whether the child died from a signal and which
+// one; a plain non-zero exit is not a crash.
+func crashSignal(ee *exec.ExitError) (string, bool) {
+	ws, ok := ee.Sys().(syscall.WaitStatus)
+	if !ok || !ws.Signaled() {
+		return "", false
+	}
+	return ws.Signal().String(), true
+}
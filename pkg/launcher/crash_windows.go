@@ -0,0 +1,33 @@
+//go:build windows
+
+package launcher
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// ntStatusNames labels the exception codes seen most often in crash
+// triage; anything else still records as a raw NTSTATUS.
+var ntStatusNames = map[uint32]string{
+	0xC0000005: "STATUS_ACCESS_VIOLATION",
+	0xC0000094: "STATUS_INTEGER_DIVIDE_BY_ZERO",
+	0xC00000FD: "STATUS_STACK_OVERFLOW",
+	0xC0000135: "STATUS_DLL_NOT_FOUND",
+	0xC0000374: "STATUS_HEAP_CORRUPTION",
+	0xC0000409: "STATUS_STACK_BUFFER_OVERRUN",
+}
+
+// crashSignal reports whether the child died from an unhandled
+// exception: Windows surfaces those as NTSTATUS exit codes in the
+// 0xC0000000 severity band.
+func crashSignal(ee *exec.ExitError) (string, bool) {
+	code := uint32(ee.ExitCode())
+	if code < 0xC0000000 {
+		return "", false
+	}
+	if name, ok := ntStatusNames[code]; ok {
+		return fmt.Sprintf("0x%08X (%s)", code, name), true
+	}
+	return fmt.Sprintf("0x%08X", code), true
+}
@@ -0,0 +1,57 @@
+package launcher
+
+import (
+	"os"
+	"strings"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+)
+
+// environ builds the entry program's environment: the inherited one,
+// filtered through the config's allow/deny patterns, plus whatever
+// the wrapper itself added (first-run marker, plugin env) — those
+// always pass, or features like WRAPPER_FIRST_RUN would silently
+// break under a tight allowlist.
+func (l *Launcher) environ(cfg *config.Config) []string {
+	inherited := os.Environ()
+	if len(cfg.Env.Allow) > 0 || len(cfg.Env.Deny) > 0 {
+		inherited = filterEnv(inherited, cfg.Env.Allow, cfg.Env.Deny)
+	}
+	return append(inherited, l.env...)
+}
+
+// filterEnv applies the allowlist (when non-empty) and then the
+// denylist to KEY=value entries.
+func filterEnv(env, allow, deny []string) []string {
+	out := env[:0:0]
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if len(allow) > 0 && !matchAnyEnv(allow, name) {
+			continue
+		}
+		if matchAnyEnv(deny, name) {
+			continue
+		}
+		out = append(out, kv)
+	}
+	return out
+}
+
+func matchAnyEnv(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if matchEnvName(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchEnvName compares case-insensitively (Windows environments are
+// case-insensitive, and config files should not have to care); a
+// trailing * makes the pattern a prefix match, e.g. "AWS_*".
+func matchEnvName(pattern, name string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix)
+	}
+	return strings.EqualFold(pattern, name)
+}
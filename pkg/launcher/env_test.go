@@ -0,0 +1,42 @@
+package launcher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+)
+
+func TestFilterEnv(t *testing.T) {
+	env := []string{
+		"PATH=/usr/bin",
+		"HOME=/home/u",
+		"HTTP_PROXY=http://proxy:3128",
+		"AWS_SECRET_ACCESS_KEY=hunter2",
+		"AWS_REGION=eu-west-1",
+		"PYTHONPATH=/opt/other",
+	}
+	got := filterEnv(env, nil, []string{"http_proxy", "AWS_*", "PYTHONPATH"})
+	want := []string{"PATH=/usr/bin", "HOME=/home/u"}
+	if strings.Join(got, ";") != strings.Join(want, ";") {
+		t.Errorf("deny filter = %v, want %v", got, want)
+	}
+
+	got = filterEnv(env, []string{"PATH", "HOME", "AWS_*"}, []string{"AWS_SECRET_ACCESS_KEY"})
+	want = []string{"PATH=/usr/bin", "HOME=/home/u", "AWS_REGION=eu-west-1"}
+	if strings.Join(got, ";") != strings.Join(want, ";") {
+		t.Errorf("allow+deny filter = %v, want %v", got, want)
+	}
+}
+
+func TestEnvironKeepsWrapperEntries(t *testing.T) {
+	l := New(WithEnv("WRAPPER_FIRST_RUN=1"))
+	cfg := &config.Config{}
+	cfg.Env.Allow = []string{"PATH"}
+	for _, kv := range l.environ(cfg) {
+		if kv == "WRAPPER_FIRST_RUN=1" {
+			return
+		}
+	}
+	t.Errorf("wrapper-added env did not survive the allowlist")
+}
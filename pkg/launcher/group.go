@@ -0,0 +1,241 @@
+package launcher
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/errcode"
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/platform"
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+)
+
+// Group launch runs a small DAG of entries — start a local backend,
+// wait for its readiness probe, then the UI on top of it. The whole
+// group is supervised together: the first exit (or Ctrl+C) takes the
+// remaining members down in reverse start order, so the backend
+// outlives the UI during shutdown rather than the other way around.
+
+// probeInterval is how often a readiness probe retries.
+const probeInterval = 200 * time.Millisecond
+
+// defaultProbeTimeout bounds a probe with no timeout_sec of its own.
+const defaultProbeTimeout = 30 * time.Second
+
+// groupMember is one running entry.
+type groupMember struct {
+	name string
+	cmd  *exec.Cmd
+}
+
+// entryExit reports one member finishing.
+type entryExit struct {
+	name string
+	err  error
+}
+
+// LaunchGroup starts cfg.Entries in dependency order and supervises
+// them until the first exit or until ctx is canceled; either way the
+// survivors are terminated in reverse start order. The returned code
+// is the exit code of the member that ended the group. extraArgs go
+// to the last entry in the config, by convention the user-facing one.
+func (l *Launcher) LaunchGroup(ctx context.Context, dest string, cfg *config.Config, extraArgs []string) (int, error) {
+	entries := selectEntries(cfg.Entries)
+	if len(entries) == 0 {
+		return 1, errcode.Wrap(errcode.EntryNotFound,
+			fmt.Errorf("no entry applies to this machine (%s/%s)", runtime.GOOS, runtime.GOARCH))
+	}
+	order, err := entryOrder(entries)
+	if err != nil {
+		return 1, errcode.Wrap(errcode.ConfigInvalid, err)
+	}
+
+	var members []groupMember
+	exited := make(chan entryExit, len(order))
+	shutdown := func() {
+		for i := len(members) - 1; i >= 0; i-- {
+			m := members[i]
+			log.Printf("stopping %s (pid %d)", m.name, m.cmd.Process.Pid)
+			platform.Processes.Terminate(m.cmd.Process)
+		}
+		// Drain so every Wait goroutine finishes before we return.
+		for range members {
+			<-exited
+		}
+	}
+
+	for _, e := range order {
+		args := append([]string(nil), e.Args...)
+		if e.Name == entries[len(entries)-1].Name {
+			args = append(args, extraArgs...)
+		}
+		cmd := exec.Command(filepath.Join(dest, filepath.FromSlash(e.Path)), args...)
+		cmd.Dir = dest
+		cmd.Env = l.environ(cfg)
+		cmd.Stdout = newLineWriter(os.Stdout, "["+e.Name+"] ")
+		cmd.Stderr = newLineWriter(os.Stderr, "["+e.Name+":err] ")
+		platform.Processes.Isolate(cmd)
+		if err := cmd.Start(); err != nil {
+			shutdown()
+			return 1, errcode.Wrap(errcode.LaunchFailed, fmt.Errorf("start %s: %w", e.Name, err))
+		}
+		if err := platform.Processes.Adopt(cmd.Process); err != nil {
+			log.Printf("process containment: %v", err)
+		}
+		log.Printf("started %s (pid %d)", e.Name, cmd.Process.Pid)
+		members = append(members, groupMember{name: e.Name, cmd: cmd})
+		name, wait := e.Name, cmd
+		go func() {
+			exited <- entryExit{name: name, err: wait.Wait()}
+		}()
+
+		if e.Ready != nil {
+			if err := waitReady(ctx, e, exited); err != nil {
+				shutdown()
+				return 1, errcode.Wrap(errcode.LaunchFailed, err)
+			}
+			log.Printf("%s is ready", e.Name)
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		log.Printf("interrupted; stopping the group")
+		shutdown()
+		return 0, ctx.Err()
+	case first := <-exited:
+		code := 0
+		if first.err != nil {
+			log.Printf("%s exited: %v", first.name, first.err)
+			if path, werr := RecordCrash(dest, first.name, first.err, nil); werr == nil && path != "" {
+				log.Printf("crash record written to %s", path)
+			}
+			if ee, ok := first.err.(*exec.ExitError); ok {
+				code = ee.ExitCode()
+			} else {
+				code = 1
+			}
+		} else {
+			log.Printf("%s exited", first.name)
+		}
+		members = removeMember(members, first.name)
+		shutdown()
+		return code, nil
+	}
+}
+
+func removeMember(members []groupMember, name string) []groupMember {
+	for i, m := range members {
+		if m.name == name {
+			return append(members[:i:i], members[i+1:]...)
+		}
+	}
+	return members
+}
+
+// waitReady polls the entry's probe until it passes, the timeout
+// elapses, the entry itself dies, or ctx is canceled. A premature
+// exit is reported through exited again for the group drain.
+func waitReady(ctx context.Context, e config.EntryConfig, exited chan entryExit) error {
+	timeout := defaultProbeTimeout
+	if e.Ready.TimeoutSec > 0 {
+		timeout = time.Duration(e.Ready.TimeoutSec) * time.Second
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		if probePasses(e.Ready) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s: not ready after %s", e.Name, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ex := <-exited:
+			if ex.name == e.Name {
+				exited <- ex
+				return fmt.Errorf("%s exited before becoming ready: %v", e.Name, ex.err)
+			}
+			exited <- ex
+		case <-time.After(probeInterval):
+		}
+	}
+}
+
+func probePasses(p *config.ReadyProbe) bool {
+	if p.TCP != "" {
+		conn, err := net.DialTimeout("tcp", p.TCP, probeInterval)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+	}
+	if p.URL != "" {
+		client := &http.Client{Timeout: 2 * time.Second}
+		resp, err := client.Get(p.URL)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return false
+		}
+	}
+	return true
+}
+
+// entryOrder returns the entries topologically sorted by their
+// "after" edges, keeping config order among independent entries, and
+// rejects unknown references and cycles.
+func entryOrder(entries []config.EntryConfig) ([]config.EntryConfig, error) {
+	byName := make(map[string]int, len(entries))
+	for i, e := range entries {
+		if e.Name == "" {
+			return nil, fmt.Errorf("entries[%d] needs a name", i)
+		}
+		if _, dup := byName[e.Name]; dup {
+			return nil, fmt.Errorf("duplicate entry name %q", e.Name)
+		}
+		byName[e.Name] = i
+	}
+	indegree := make([]int, len(entries))
+	dependents := make([][]int, len(entries))
+	for i, e := range entries {
+		for _, dep := range e.After {
+			j, ok := byName[dep]
+			if !ok {
+				return nil, fmt.Errorf("entry %q: unknown dependency %q", e.Name, dep)
+			}
+			indegree[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+	var order []config.EntryConfig
+	done := make([]bool, len(entries))
+	for len(order) < len(entries) {
+		advanced := false
+		for i, e := range entries {
+			if done[i] || indegree[i] > 0 {
+				continue
+			}
+			order = append(order, e)
+			done[i] = true
+			for _, d := range dependents[i] {
+				indegree[d]--
+			}
+			advanced = true
+		}
+		if !advanced {
+			return nil, fmt.Errorf("entries contain a dependency cycle")
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,49 @@
+package launcher
+
+import (
+	"testing"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+)
+
+func TestEntryOrder(t *testing.T) {
+	entries := []config.EntryConfig{
+		{Name: "ui", After: []string{"api"}},
+		{Name: "db"},
+		{Name: "api", After: []string{"db"}},
+	}
+	order, err := entryOrder(entries)
+	if err != nil {
+		t.Fatalf("entryOrder: %v", err)
+	}
+	got := make([]string, len(order))
+	for i, e := range order {
+		got[i] = e.Name
+	}
+	want := []string{"db", "api", "ui"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestEntryOrderRejectsBadConfigs(t *testing.T) {
+	if _, err := entryOrder([]config.EntryConfig{
+		{Name: "a", After: []string{"missing"}},
+	}); err == nil {
+		t.Fatal("unknown dependency not rejected")
+	}
+	if _, err := entryOrder([]config.EntryConfig{
+		{Name: "a", After: []string{"b"}},
+		{Name: "b", After: []string{"a"}},
+	}); err == nil {
+		t.Fatal("cycle not rejected")
+	}
+	if _, err := entryOrder([]config.EntryConfig{
+		{Name: "a"},
+		{Name: "a"},
+	}); err == nil {
+		t.Fatal("duplicate name not rejected")
+	}
+}
@@ -0,0 +1,155 @@
+// Package launcher starts a synced payload's entry program, either
+// waiting for it (propagating the exit code) or detached. It is the
+// launch half of the embeddable sync-and-launch engine.
+package launcher
+
+import (
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/errcode"
+	"github.com/doudou0720/Aiwb_0Installer_Feed/internal/platform"
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Launcher is the stable embedding surface for starting entry
+// programs: a constructor, functional options, and Launch/Start. The
+// exported API of pkg/... follows semantic versioning: within a
+// major version options may be added but never removed or
+// repurposed.
+type Launcher struct {
+	env []string
+}
+
+// Option configures a Launcher.
+type Option func(*Launcher)
+
+// WithEnv appends KEY=value entries to the environment every entry
+// program started by this Launcher receives.
+func WithEnv(kv ...string) Option {
+	return func(l *Launcher) { l.env = append(l.env, kv...) }
+}
+
+// New returns a Launcher with the given options applied.
+func New(opts ...Option) *Launcher {
+	l := &Launcher{}
+	for _, o := range opts {
+		o(l)
+	}
+	return l
+}
+
+// defaultLauncher backs the package-level calls the wrapper command
+// uses; AppendEnv mutates it.
+var defaultLauncher = &Launcher{}
+
+// AppendEnv adds a KEY=value entry to the environment every entry
+// program launched through the package-level calls receives
+// (e.g. WRAPPER_FIRST_RUN=1).
+func AppendEnv(kv string) {
+	defaultLauncher.env = append(defaultLauncher.env, kv)
+}
+
+// Launch runs the default Launcher; see Launcher.Launch.
+func Launch(dest string, cfg *config.Config, extraArgs []string) (int, error) {
+	return defaultLauncher.Launch(dest, cfg, extraArgs)
+}
+
+// StartDetached runs the default Launcher; see Launcher.Start.
+func StartDetached(dest string, cfg *config.Config, extraArgs []string) (*exec.Cmd, error) {
+	return defaultLauncher.Start(dest, cfg, extraArgs)
+}
+
+// LaunchGroup runs the default Launcher; see Launcher.LaunchGroup.
+func LaunchGroup(ctx context.Context, dest string, cfg *config.Config, extraArgs []string) (int, error) {
+	return defaultLauncher.LaunchGroup(ctx, dest, cfg, extraArgs)
+}
+
+// Launch starts the entry program from dest. When cfg.Wait is set
+// the caller blocks until the child exits and gets its exit code;
+// otherwise the child is left running detached and 0 is returned.
+func (l *Launcher) Launch(dest string, cfg *config.Config, extraArgs []string) (int, error) {
+	entry := filepath.Join(dest, filepath.FromSlash(cfg.Entry))
+	if _, err := os.Stat(entry); err != nil {
+		return 1, errcode.Wrap(errcode.EntryNotFound, fmt.Errorf("entry program %s not found: %w", entry, err))
+	}
+	args := append(append([]string{}, cfg.Args...), extraArgs...)
+	cmd := exec.Command(entry, args...)
+	cmd.Dir = dest
+	cmd.Env = l.environ(cfg)
+
+	if cfg.Wait {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		cmd.Stdin = os.Stdin
+		if cfg.TeeOutput {
+			// Tee failure downgrades to plain passthrough: losing the
+			// captured log files is not worth refusing to launch.
+			if stdout, stderr, cleanup, err := teeOutputs(dest, teeName(cfg)); err != nil {
+				log.Printf("tee: %v", err)
+			} else {
+				cmd.Stdout, cmd.Stderr = stdout, stderr
+				defer cleanup()
+			}
+		}
+		// The output tail rides along for the crash record.
+		tail := newTailBuffer(crashTailLines)
+		cmd.Stdout = io.MultiWriter(cmd.Stdout, tail)
+		cmd.Stderr = io.MultiWriter(cmd.Stderr, tail)
+		if err := cmd.Run(); err != nil {
+			if ee, ok := err.(*exec.ExitError); ok {
+				if path, werr := RecordCrash(dest, cfg.Entry, ee, tail.Lines()); werr != nil {
+					log.Printf("crash record: %v", werr)
+				} else if path != "" {
+					log.Printf("entry crashed; record written to %s", path)
+				}
+				return ee.ExitCode(), nil
+			}
+			return 1, errcode.Wrap(errcode.LaunchFailed, fmt.Errorf("start entry: %w", err))
+		}
+		return 0, nil
+	}
+
+	started, err := l.Start(dest, cfg, extraArgs)
+	if err != nil {
+		return 1, err
+	}
+	started.Process.Release()
+	return 0, nil
+}
+
+// Start starts the entry program without waiting and returns the
+// command so callers that stay resident (daemon mode) can monitor or
+// restart it.
+func (l *Launcher) Start(dest string, cfg *config.Config, extraArgs []string) (*exec.Cmd, error) {
+	entry := filepath.Join(dest, filepath.FromSlash(cfg.Entry))
+	args := append(append([]string{}, cfg.Args...), extraArgs...)
+	cmd := exec.Command(entry, args...)
+	cmd.Dir = dest
+	cmd.Env = l.environ(cfg)
+	platform.Processes.Isolate(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, errcode.Wrap(errcode.LaunchFailed, fmt.Errorf("start entry: %w", err))
+	}
+	// Containment is best-effort: a failed job-object assignment
+	// still leaves a running, monitorable child.
+	if err := platform.Processes.Adopt(cmd.Process); err != nil {
+		log.Printf("process containment: %v", err)
+	}
+	log.Printf("started %s (pid %d)", cfg.Entry, cmd.Process.Pid)
+	lastPID = cmd.Process.Pid
+	return cmd, nil
+}
+
+// lastPID remembers the most recently started entry program so the
+// wrapper can report it after a detached launch.
+var lastPID int
+
+// LastPID returns the process ID of the last entry program started
+// through this package, or 0 when none has been.
+func LastPID() int { return lastPID }
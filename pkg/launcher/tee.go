@@ -0,0 +1,103 @@
+package launcher
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+)
+
+// Tee mode serves two audiences at once: a human watching the
+// console gets both of the child's streams interleaved with
+// [name]/[name:err] prefixes so the combined log stays readable, and
+// tooling gets the raw, unprefixed streams as files under
+// dest/.wrapper-logs (truncated each run, left out of mirror's sweep
+// by the metadata prefix).
+
+// logsDirName holds the raw per-run stream captures under dest.
+const logsDirName = ".wrapper-logs"
+
+// lineWriter forwards writes to w, inserting prefix at the start of
+// every line. Partial lines are buffered until their newline (or
+// Close) arrives, so interleaved stdout/stderr stay line-atomic.
+type lineWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	prefix string
+	line   []byte
+}
+
+func newLineWriter(w io.Writer, prefix string) *lineWriter {
+	return &lineWriter{w: w, prefix: prefix}
+}
+
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	for _, b := range p {
+		lw.line = append(lw.line, b)
+		if b == '\n' {
+			if err := lw.flushLine(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(p), nil
+}
+
+// Close flushes a trailing line the child never terminated.
+func (lw *lineWriter) Close() error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if len(lw.line) == 0 {
+		return nil
+	}
+	lw.line = append(lw.line, '\n')
+	return lw.flushLine()
+}
+
+func (lw *lineWriter) flushLine() error {
+	_, err := lw.w.Write(append([]byte(lw.prefix), lw.line...))
+	lw.line = lw.line[:0]
+	return err
+}
+
+// teeOutputs returns the stdout/stderr writers for tee mode and a
+// cleanup func that flushes partial lines and closes the raw files.
+func teeOutputs(dest, name string) (stdout, stderr io.Writer, cleanup func(), err error) {
+	dir := filepath.Join(dest, logsDirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, nil, nil, err
+	}
+	outFile, err := os.Create(filepath.Join(dir, "stdout.log"))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	errFile, err := os.Create(filepath.Join(dir, "stderr.log"))
+	if err != nil {
+		outFile.Close()
+		return nil, nil, nil, err
+	}
+	outLines := newLineWriter(os.Stdout, "["+name+"] ")
+	errLines := newLineWriter(os.Stderr, "["+name+":err] ")
+	cleanup = func() {
+		outLines.Close()
+		errLines.Close()
+		outFile.Close()
+		errFile.Close()
+	}
+	return io.MultiWriter(outLines, outFile), io.MultiWriter(errLines, errFile), cleanup, nil
+}
+
+// teeName is the console prefix label: the install name when set,
+// otherwise the entry program without its extension.
+func teeName(cfg *config.Config) string {
+	if cfg.Name != "" {
+		return cfg.Name
+	}
+	base := filepath.Base(cfg.Entry)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
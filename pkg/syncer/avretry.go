@@ -0,0 +1,68 @@
+package syncer
+
+import (
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Antivirus interference: real-time scanners open freshly written
+// executables for inspection, and for a moment the file is locked —
+// the copy then fails with a sharing violation or access denied even
+// though nothing is actually wrong. Each affected copy is retried
+// within a window (av_retry_sec, default 2s), and when the pattern
+// repeats across several files the sync ends with targeted guidance
+// instead of leaving the user to blame the payload.
+
+// avRetryInterval paces retries of a scanner-locked file.
+const avRetryInterval = 250 * time.Millisecond
+
+// avGuidanceThreshold is how many scanner-locked copies it takes
+// before the guidance message is worth printing.
+const avGuidanceThreshold = 3
+
+// avRetryWindow returns how long one file's copy keeps retrying
+// transient locks.
+func avRetryWindow(cfg *config.Config) time.Duration {
+	if cfg.AVRetrySec > 0 {
+		return time.Duration(cfg.AVRetrySec) * time.Second
+	}
+	return 2 * time.Second
+}
+
+// copyWithRetry copies like CopyFile but keeps retrying transient
+// sharing violations until the window elapses, counting every lock it
+// saw in *locks for the interference report.
+func copyWithRetry(src, dest string, info os.FileInfo, window time.Duration, locks *int64) error {
+	deadline := time.Now().Add(window)
+	for {
+		err := CopyFile(src, dest, info)
+		if err == nil || !isTransientLock(err) {
+			return err
+		}
+		atomic.AddInt64(locks, 1)
+		if time.Now().After(deadline) {
+			return err
+		}
+		debugf(2, "retry %s: transient lock (%v)", dest, err)
+		time.Sleep(avRetryInterval)
+	}
+}
+
+// reportAVInterference prints guidance when enough copies hit
+// scanner-style locks during one sync, naming the likely scanner
+// when a known one is running.
+func reportAVInterference(dest string, locks int64) {
+	if locks < avGuidanceThreshold {
+		return
+	}
+	msg := fmt.Sprintf("%d copies hit sharing violations on freshly written files — real-time antivirus scanning is likely intercepting them", locks)
+	if name := likelyScanner(); name != "" {
+		msg += fmt.Sprintf(" (%s is running)", name)
+	}
+	log.Printf("%s; consider excluding %s from real-time scanning or raising av_retry_sec", msg, dest)
+}
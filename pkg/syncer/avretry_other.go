@@ -0,0 +1,11 @@
+//go:build !windows
+
+package syncer
+
+// isTransientLock: Unix filesystems do not lock files against open
+// writers the way Windows sharing modes do, so there is nothing to
+// retry here.
+func isTransientLock(error) bool { return false }
+
+// likelyScanner has no portable answer off Windows.
+func likelyScanner() string { return "" }
@@ -0,0 +1,62 @@
+//go:build windows
+
+package syncer
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// isTransientLock reports whether err is the kind of failure a
+// real-time scanner causes on a freshly written file: a sharing or
+// lock violation, or access denied on a file we just created.
+func isTransientLock(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	switch errno {
+	case windows.ERROR_SHARING_VIOLATION, windows.ERROR_LOCK_VIOLATION, windows.ERROR_ACCESS_DENIED:
+		return true
+	}
+	return false
+}
+
+// avProcessNames maps service executables of common scanners to a
+// name worth showing the user.
+var avProcessNames = map[string]string{
+	"msmpeng.exe":         "Microsoft Defender",
+	"avp.exe":             "Kaspersky",
+	"avastsvc.exe":        "Avast",
+	"avgsvc.exe":          "AVG",
+	"mcshield.exe":        "McAfee",
+	"bdservicehost.exe":   "Bitdefender",
+	"ekrn.exe":            "ESET",
+	"ccsvchst.exe":        "Norton",
+	"sentinelagent.exe":   "SentinelOne",
+	"csfalconservice.exe": "CrowdStrike Falcon",
+}
+
+// likelyScanner walks the process list and returns the first known
+// scanner found; enumeration works without extra privileges because
+// only names are read, not handles.
+func likelyScanner() string {
+	snap, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return ""
+	}
+	defer windows.CloseHandle(snap)
+	var entry windows.ProcessEntry32
+	entry.Size = uint32(unsafe.Sizeof(entry))
+	for err = windows.Process32First(snap, &entry); err == nil; err = windows.Process32Next(snap, &entry) {
+		name := strings.ToLower(windows.UTF16ToString(entry.ExeFile[:]))
+		if av, ok := avProcessNames[name]; ok {
+			return av
+		}
+	}
+	return ""
+}
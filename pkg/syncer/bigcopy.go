@@ -0,0 +1,160 @@
+package syncer
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// Huge assets get their own copy strategy. The regular path writes a
+// temp file that is discarded on failure, which for a 10 GB file means
+// an interrupted sync starts over from byte zero. Above
+// bigFileThreshold the copy goes through a persistent partial file
+// plus a journal of per-chunk checksums instead, so the next run
+// verifies what landed and resumes after the last chunk that is known
+// good.
+
+const (
+	// bigFileThreshold selects the chunked path.
+	bigFileThreshold = 256 << 20
+	// copyChunkSize is the resume granularity.
+	copyChunkSize = 16 << 20
+
+	// ChunkPartialSuffix and ChunkJournalSuffix mark in-progress
+	// chunked copies; gc uses them for stale-partial cleanup.
+	ChunkPartialSuffix = ".wrapper-chunks"
+	ChunkJournalSuffix = ".wrapper-chunks.journal"
+)
+
+// copyFileChunked copies src to dest in checksummed chunks. The
+// journal's header line pins the source identity (size and mtime), so
+// a partial from an older source version is thrown away rather than
+// resumed into a frankenfile.
+func copyFileChunked(src, dest string, info os.FileInfo) error {
+	partial := dest + ChunkPartialSuffix
+	journal := dest + ChunkJournalSuffix
+	header := fmt.Sprintf("%d %d %d", info.Size(), info.ModTime().UnixNano(), copyChunkSize)
+
+	offset := resumeOffset(partial, journal, header)
+	in, err := fsys.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if _, err := in.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	out, err := fsys.OpenFile(partial, os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	if err := out.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err := out.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	jf, err := fsys.OpenFile(journal, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer jf.Close()
+	if offset == 0 {
+		if err := jf.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(jf, header); err != nil {
+			return err
+		}
+	}
+
+	buf := HashBufPool.Get().(*[]byte)
+	defer HashBufPool.Put(buf)
+	for offset < info.Size() {
+		h := sha256.New()
+		n, err := io.CopyBuffer(io.MultiWriter(out, h), io.LimitReader(in, copyChunkSize), *buf)
+		if err != nil {
+			return err
+		}
+		// The chunk's data must be durable before its journal entry
+		// claims it is, or a crash could journal a chunk the disk
+		// never got.
+		if err := out.Sync(); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(jf, hex.EncodeToString(h.Sum(nil))); err != nil {
+			return err
+		}
+		offset += n
+	}
+
+	if err := out.Close(); err != nil {
+		return err
+	}
+	jf.Close()
+	if err := fsys.Chmod(partial, info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := fsys.Chtimes(partial, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	if err := fsys.Rename(partial, dest); err != nil {
+		return err
+	}
+	fsys.Remove(journal)
+	return nil
+}
+
+// resumeOffset reports how many bytes of partial are covered by
+// journaled chunks that still hash correctly. Anything — a missing
+// journal, a header from a different source version, a chunk whose
+// checksum no longer matches — ends the verified prefix there.
+func resumeOffset(partial, journal, header string) int64 {
+	jf, err := fsys.Open(journal)
+	if err != nil {
+		return 0
+	}
+	defer jf.Close()
+	pf, err := fsys.Open(partial)
+	if err != nil {
+		return 0
+	}
+	defer pf.Close()
+
+	sc := bufio.NewScanner(jf)
+	if !sc.Scan() || strings.TrimSpace(sc.Text()) != header {
+		return 0
+	}
+	buf := HashBufPool.Get().(*[]byte)
+	defer HashBufPool.Put(buf)
+	var offset int64
+	verified := []string{header}
+	for sc.Scan() {
+		h := sha256.New()
+		n, err := io.CopyBuffer(h, io.LimitReader(pf, copyChunkSize), *buf)
+		if err != nil || n == 0 {
+			break
+		}
+		if hex.EncodeToString(h.Sum(nil)) != strings.TrimSpace(sc.Text()) {
+			break
+		}
+		verified = append(verified, strings.TrimSpace(sc.Text()))
+		offset += n
+	}
+	if offset == 0 {
+		return 0
+	}
+	// Rewrite the journal down to the verified prefix so entries past
+	// a bad chunk cannot be trusted by a later resume.
+	if err := fsys.WriteFile(journal, []byte(strings.Join(verified, "\n")+"\n"), 0o644); err != nil {
+		return 0
+	}
+	log.Printf("resuming copy of %s at byte %d", partial, offset)
+	return offset
+}
@@ -0,0 +1,25 @@
+package syncer
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// nativeCopyFile clones src with clonefile(2): on APFS the copy is a
+// constant-time copy-on-write clone with metadata included, no matter
+// how large the file. It reports handled=false to fall back to the
+// portable path across volumes or on non-APFS filesystems.
+func nativeCopyFile(src, dest string, info os.FileInfo) (handled bool, err error) {
+	tmp := filepath.Join(filepath.Dir(dest), ".wrapper-tmp-"+filepath.Base(dest))
+	os.Remove(tmp)
+	if err := unix.Clonefile(src, tmp, unix.CLONE_NOFOLLOW); err != nil {
+		return false, nil
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return true, err
+	}
+	return true, nil
+}
@@ -0,0 +1,57 @@
+package syncer
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// nativeCopyFile copies src with copy_file_range(2), which stays in
+// the kernel — no userspace buffer — and lets filesystems that
+// support it (btrfs, XFS, NFS 4.2) reflink or server-side-copy
+// instead of moving bytes. It reports handled=false to fall back to
+// the portable path on kernels or filesystem pairs that refuse the
+// call.
+func nativeCopyFile(src, dest string, info os.FileInfo) (handled bool, err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return true, err
+	}
+	defer in.Close()
+	tmp := filepath.Join(filepath.Dir(dest), ".wrapper-tmp-"+filepath.Base(dest))
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return true, err
+	}
+	remaining := info.Size()
+	for remaining > 0 {
+		n, cerr := unix.CopyFileRange(int(in.Fd()), nil, int(out.Fd()), nil, int(remaining), 0)
+		if cerr != nil {
+			out.Close()
+			os.Remove(tmp)
+			switch cerr {
+			case unix.ENOSYS, unix.EXDEV, unix.EINVAL, unix.EOPNOTSUPP:
+				return false, nil
+			}
+			return true, cerr
+		}
+		if n == 0 {
+			break
+		}
+		remaining -= int64(n)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return true, err
+	}
+	if err := os.Chtimes(tmp, info.ModTime(), info.ModTime()); err != nil {
+		os.Remove(tmp)
+		return true, err
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return true, err
+	}
+	return true, nil
+}
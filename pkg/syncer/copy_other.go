@@ -0,0 +1,11 @@
+//go:build !windows && !linux && !darwin
+
+package syncer
+
+import "os"
+
+// nativeCopyFile has no acceleration to offer here; everything goes
+// down the portable path.
+func nativeCopyFile(src, dest string, info os.FileInfo) (handled bool, err error) {
+	return false, nil
+}
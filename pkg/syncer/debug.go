@@ -0,0 +1,23 @@
+package syncer
+
+// Debug output mirrors the events sink: the engine describes what it
+// is doing and why, and the consumer decides whether anyone is
+// listening. Level 1 is per-file decisions (why a file was skipped);
+// level 2 is full detail (hash comparisons, retry attempts).
+
+// debugFn is nil unless the consumer installed a sink.
+var debugFn func(level int, format string, args ...any)
+
+// SetDebug installs the debug sink and returns the previous one. A
+// nil fn silences debug output again.
+func SetDebug(fn func(level int, format string, args ...any)) func(level int, format string, args ...any) {
+	prev := debugFn
+	debugFn = fn
+	return prev
+}
+
+func debugf(level int, format string, args ...any) {
+	if debugFn != nil {
+		debugFn(level, format, args...)
+	}
+}
@@ -0,0 +1,98 @@
+package syncer
+
+import (
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Intra-dest deduplication: payloads often bundle the same runtime
+// several times over (one copy per plugin). After a sync, identical
+// files can safely share one inode — the copy pass always replaces
+// content via a temp file and rename, so writing one path never
+// bleeds into its link siblings.
+
+// dedupMinSize keeps the pass to files where the hashing pays off.
+const dedupMinSize = 64 << 10
+
+// DedupDest hardlinks byte-identical files within dest and returns
+// the number of duplicates collapsed and the bytes saved. Protected
+// paths and the wrapper's own bookkeeping are left alone; filesystems
+// without hardlinks simply yield zero links.
+func DedupDest(dest string, cfg *config.Config) (int, int64, error) {
+	if _, onDisk := fsys.(osFS); !onDisk {
+		return 0, 0, nil
+	}
+	type candidate struct {
+		rel  string
+		info os.FileInfo
+	}
+	bySize := make(map[int64][]candidate)
+	err := walkTree(context.Background(), dest, func(rel string, info os.FileInfo) error {
+		if strings.HasPrefix(rel, MetaPrefix) {
+			return nil
+		}
+		if rel == DownloadsDirName || strings.HasPrefix(rel, StagingDirPrefix) {
+			return filepath.SkipDir
+		}
+		if Protected(rel, cfg.Protected) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() && info.Mode().IsRegular() && info.Size() >= dedupMinSize {
+			bySize[info.Size()] = append(bySize[info.Size()], candidate{rel, info})
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	links := 0
+	var saved int64
+	for _, group := range bySize {
+		if len(group) < 2 {
+			continue
+		}
+		first := make(map[string]candidate)
+		for _, c := range group {
+			path := filepath.Join(dest, filepath.FromSlash(c.rel))
+			digest, err := FileSHA256(path)
+			if err != nil {
+				continue
+			}
+			keep, ok := first[digest]
+			if !ok {
+				first[digest] = c
+				continue
+			}
+			keepPath := filepath.Join(dest, filepath.FromSlash(keep.rel))
+			if ki, err := os.Stat(keepPath); err == nil && os.SameFile(ki, c.info) {
+				continue
+			}
+			// Stamp the later mtime on the shared inode so neither
+			// path looks outdated to the next sync.
+			mtime := keep.info.ModTime()
+			if c.info.ModTime().After(mtime) {
+				mtime = c.info.ModTime()
+			}
+			os.Remove(path)
+			if err := os.Link(keepPath, path); err != nil {
+				// No hardlinks here; put the bytes back.
+				if cerr := CopyFile(keepPath, path, c.info); cerr != nil {
+					return links, saved, cerr
+				}
+				continue
+			}
+			os.Chtimes(path, mtime, mtime)
+			links++
+			saved += c.info.Size()
+		}
+	}
+	return links, saved, nil
+}
@@ -0,0 +1,60 @@
+package syncer
+
+// Progress reporting mirrors the tracing hook: the engine emits
+// callbacks and stays ignorant of what consumes them. The CLI logs,
+// a GUI can drive a progress bar, embedders can do whatever they
+// like — none of it is welded to log.Printf in here.
+
+// Events receives engine progress callbacks. Implementations must be
+// safe for concurrent use: file callbacks arrive from the parallel
+// copy workers.
+type Events interface {
+	// OnPhaseChange marks entry into a pipeline phase: "walk",
+	// "copy", "mirror".
+	OnPhaseChange(phase string)
+
+	// OnFileStart fires when a worker picks up one file.
+	OnFileStart(rel string, size int64)
+
+	// OnFileDone fires when that file finished; err is nil for a
+	// successful copy.
+	OnFileDone(rel string, size int64, err error)
+
+	// OnError reports a non-fatal engine error (a failed file copy);
+	// fatal ones still come back from SyncDir itself.
+	OnError(err error)
+}
+
+// events is nil unless the consumer installed a sink.
+var events Events
+
+// SetEvents installs the progress sink and returns the previous one.
+func SetEvents(e Events) Events {
+	prev := events
+	events = e
+	return prev
+}
+
+func emitPhase(phase string) {
+	if events != nil {
+		events.OnPhaseChange(phase)
+	}
+}
+
+func emitFileStart(rel string, size int64) {
+	if events != nil {
+		events.OnFileStart(rel, size)
+	}
+}
+
+func emitFileDone(rel string, size int64, err error) {
+	if events != nil {
+		events.OnFileDone(rel, size, err)
+	}
+}
+
+func emitError(err error) {
+	if events != nil {
+		events.OnError(err)
+	}
+}
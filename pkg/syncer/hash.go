@@ -0,0 +1,47 @@
+package syncer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sync"
+)
+
+// HashBufPool reuses 1 MiB copy buffers across hashing, copy and
+// download paths, so a multi-GB payload is streamed in large reads
+// without allocating a fresh buffer per file.
+var HashBufPool = sync.Pool{
+	New: func() any { b := make([]byte, 1<<20); return &b },
+}
+
+// digestCache remembers digests computed while data was already
+// streaming past — a download hashed on the wire — keyed by the final
+// file path. Only immutable completed files are recorded, so a cached
+// entry never goes stale.
+var digestCache sync.Map
+
+// RecordFileDigest notes the digest of a completed, immutable file.
+func RecordFileDigest(path, digest string) {
+	digestCache.Store(path, digest)
+}
+
+// FileSHA256 returns the lowercase hex SHA-256 of a file, without a
+// read pass when the digest was already computed while writing it.
+func FileSHA256(path string) (string, error) {
+	if d, ok := digestCache.Load(path); ok {
+		return d.(string), nil
+	}
+	f, err := fsys.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	buf := HashBufPool.Get().(*[]byte)
+	_, err = io.CopyBuffer(h, f, *buf)
+	HashBufPool.Put(buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
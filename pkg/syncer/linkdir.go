@@ -0,0 +1,50 @@
+package syncer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Link-instead-of-copy subtrees: a payload's multi-GB static data
+// (assets, media) can be served straight out of the read-only source
+// through a directory junction on Windows or a symlink elsewhere,
+// so it never gets duplicated into dest while writable parts are
+// still copied. Meant for stable local sources — a linked staging
+// directory would dangle once it is cleaned up.
+
+// isLinkDir reports whether the source-relative rel is one of the
+// configured link_dirs entries.
+func isLinkDir(rel string, dirs []string) bool {
+	slashRel := filepath.ToSlash(rel)
+	for _, d := range dirs {
+		if strings.Trim(filepath.ToSlash(d), "/") == slashRel {
+			return true
+		}
+	}
+	return false
+}
+
+// linkDirInto puts a directory link at dest/rel pointing into the
+// source, replacing a previously copied real directory. An existing
+// link with the right target is left alone.
+func linkDirInto(src, dest, rel string) error {
+	sp, err := filepath.Abs(filepath.Join(src, rel))
+	if err != nil {
+		return err
+	}
+	dp := filepath.Join(dest, rel)
+	if target, err := os.Readlink(dp); err == nil {
+		if target == sp {
+			return nil
+		}
+	}
+	if err := os.RemoveAll(dp); err != nil {
+		return err
+	}
+	if err := createDirLink(sp, dp); err != nil {
+		return fmt.Errorf("link %s: %w", rel, err)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package syncer
+
+import "os"
+
+// createDirLink makes a directory symlink; junctions are a Windows
+// notion.
+func createDirLink(target, link string) error {
+	return os.Symlink(target, link)
+}
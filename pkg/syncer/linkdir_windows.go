@@ -0,0 +1,21 @@
+//go:build windows
+
+package syncer
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// createDirLink makes a directory junction: unlike a directory
+// symlink it needs no privilege, and the target stays resolvable for
+// every process on the machine. The Go standard library cannot create
+// junctions, so this shells out to cmd's mklink.
+func createDirLink(target, link string) error {
+	out, err := exec.Command("cmd", "/c", "mklink", "/J", link, target).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("mklink /J: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
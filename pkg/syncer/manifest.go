@@ -0,0 +1,96 @@
+package syncer
+
+import (
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Install manifests: a payload can ship a file listing exactly what
+// the entry program needs at run time, and the wrapper syncs only
+// those paths — docs, tests and SDK content stay behind. The -full
+// flag overrides for a complete copy.
+
+// manifest is the parsed include list. Entries match like protected
+// patterns: a plain path covers its subtree, a glob is tried against
+// the full relative path and the base name.
+type manifest struct {
+	entries []string
+	globs   bool
+}
+
+// loadManifest reads the configured manifest from the source tree,
+// with the entry programs appended so a packager cannot sync an
+// install that lacks its own executable. It returns nil — no
+// filtering — when no manifest is configured or cfg.Full is set.
+func loadManifest(src string, cfg *config.Config) (*manifest, error) {
+	if cfg.Manifest == "" || cfg.Full {
+		return nil, nil
+	}
+	data, err := fsys.ReadFile(filepath.Join(src, filepath.FromSlash(cfg.Manifest)))
+	if err != nil {
+		return nil, fmt.Errorf("install manifest: %w", err)
+	}
+	m := &manifest{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m.add(line)
+	}
+	if len(m.entries) == 0 {
+		return nil, fmt.Errorf("install manifest %s lists no files", cfg.Manifest)
+	}
+	m.add(cfg.Manifest)
+	if cfg.Entry != "" {
+		m.add(cfg.Entry)
+	}
+	for _, e := range cfg.Entries {
+		if e.Path != "" {
+			m.add(e.Path)
+		}
+	}
+	return m, nil
+}
+
+func (m *manifest) add(entry string) {
+	entry = strings.Trim(filepath.ToSlash(entry), "/")
+	if entry == "" {
+		return
+	}
+	if strings.ContainsAny(entry, "*?[") {
+		m.globs = true
+	}
+	m.entries = append(m.entries, entry)
+}
+
+// wantsFile reports whether the manifest includes the file rel.
+func (m *manifest) wantsFile(rel string) bool {
+	slashRel := filepath.ToSlash(rel)
+	for _, e := range m.entries {
+		if matchPathPattern(e, slashRel) {
+			return true
+		}
+	}
+	return false
+}
+
+// wantsDir reports whether anything under the directory rel could be
+// included, so the walk can prune whole unwanted subtrees. Globs
+// defeat pruning — a pattern like "*.dll" may match anywhere — and
+// make every directory worth descending into.
+func (m *manifest) wantsDir(rel string) bool {
+	if m.globs {
+		return true
+	}
+	slashRel := filepath.ToSlash(rel)
+	for _, e := range m.entries {
+		if matchPathPattern(e, slashRel) || strings.HasPrefix(e, slashRel+"/") {
+			return true
+		}
+	}
+	return false
+}
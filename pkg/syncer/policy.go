@@ -0,0 +1,86 @@
+package syncer
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Per-path policies: the config's "paths" section maps globs to how
+// that part of the payload is materialized in dest, in one place
+// instead of spreading the same intent over excludes, protected and
+// link_dirs. Policies:
+//
+//	copy     the default copy behaviour, useful to carve exceptions
+//	         out of a broader pattern
+//	link     serve the directory straight from the source via a
+//	         junction or symlink (directories only; see link_dirs)
+//	skip     never copy it, and leave whatever dest has alone
+//	protect  copy it once but never overwrite or delete user changes
+//	mirror   delete strays under it even when the global mode is copy
+const (
+	PolicyCopy    = "copy"
+	PolicyLink    = "link"
+	PolicySkip    = "skip"
+	PolicyProtect = "protect"
+	PolicyMirror  = "mirror"
+)
+
+// validatePathPolicies rejects unknown policy names up front, before
+// a typo silently turns into default behaviour.
+func validatePathPolicies(paths map[string]string) error {
+	for pat, pol := range paths {
+		switch pol {
+		case PolicyCopy, PolicyLink, PolicySkip, PolicyProtect, PolicyMirror:
+		default:
+			return fmt.Errorf("paths[%q]: unknown policy %q", pat, pol)
+		}
+	}
+	return nil
+}
+
+// pathPolicy returns the policy for the source-relative rel, or ""
+// when no pattern matches. Patterns match like protected entries — a
+// plain path covers its whole subtree, a glob is tried against the
+// full relative path and the base name — and the longest matching
+// pattern wins, so "assets" can be linked while "assets/config" stays
+// copied.
+func pathPolicy(rel string, paths map[string]string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	slashRel := filepath.ToSlash(rel)
+	best, bestLen := "", -1
+	for pat, pol := range paths {
+		p := strings.Trim(filepath.ToSlash(pat), "/")
+		if p == "" || !matchPathPattern(p, slashRel) {
+			continue
+		}
+		if len(p) > bestLen {
+			best, bestLen = pol, len(p)
+		}
+	}
+	return best
+}
+
+func matchPathPattern(p, slashRel string) bool {
+	if strings.ContainsAny(p, "*?[") {
+		if ok, _ := path.Match(p, slashRel); ok {
+			return true
+		}
+		ok, _ := path.Match(p, path.Base(slashRel))
+		return ok
+	}
+	return slashRel == p || strings.HasPrefix(slashRel, p+"/")
+}
+
+// hasPolicy reports whether any pattern carries the given policy.
+func hasPolicy(paths map[string]string, pol string) bool {
+	for _, p := range paths {
+		if p == pol {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package syncer
+
+import (
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Rename detection: when a new payload version merely moves or
+// renames a large file, the bytes are usually still sitting in dest
+// under the old path. Instead of copying them again from the source,
+// the copy pass consults a size-then-hash index of dest and hardlinks
+// the existing content into place; the stale path disappears in the
+// mirror pass as usual.
+
+// renameMinSize keeps the index to files worth the hashing effort.
+const renameMinSize = 1 << 20
+
+// renameIndex maps content already present in dest. Candidates are
+// grouped by size during one cheap walk; hashing happens lazily, only
+// for sizes the copy pass actually asks about.
+type renameIndex struct {
+	dest   string
+	bySize map[int64][]string
+
+	mu     sync.Mutex
+	hashed map[string]string // rel -> digest, "" when unreadable
+}
+
+// newRenameIndex walks dest and records link candidates. It returns
+// nil — disabling detection — unless cfg asks for it and the engine
+// runs on the real filesystem, where hardlinks exist.
+func newRenameIndex(dest string, cfg *config.Config) *renameIndex {
+	if !cfg.DetectRenames {
+		return nil
+	}
+	if _, onDisk := fsys.(osFS); !onDisk {
+		return nil
+	}
+	ix := &renameIndex{
+		dest:   dest,
+		bySize: make(map[int64][]string),
+		hashed: make(map[string]string),
+	}
+	err := walkTree(context.Background(), dest, func(rel string, info os.FileInfo) error {
+		if strings.HasPrefix(rel, MetaPrefix) {
+			return nil
+		}
+		if rel == DownloadsDirName || strings.HasPrefix(rel, StagingDirPrefix) {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && info.Size() >= renameMinSize {
+			ix.bySize[info.Size()] = append(ix.bySize[info.Size()], filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil || len(ix.bySize) == 0 {
+		return nil
+	}
+	return ix
+}
+
+// lookup returns the dest-relative path of a file whose content is
+// identical to the source file sp, or ok=false.
+func (ix *renameIndex) lookup(sp, rel string, info os.FileInfo) (string, bool) {
+	if info.Size() < renameMinSize {
+		return "", false
+	}
+	cands := ix.bySize[info.Size()]
+	if len(cands) == 0 {
+		return "", false
+	}
+	srcHash, err := FileSHA256(sp)
+	if err != nil {
+		return "", false
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for _, c := range cands {
+		if c == filepath.ToSlash(rel) {
+			continue
+		}
+		h, done := ix.hashed[c]
+		if !done {
+			h, err = FileSHA256(filepath.Join(ix.dest, filepath.FromSlash(c)))
+			if err != nil {
+				h = ""
+			}
+			ix.hashed[c] = h
+		}
+		if h != "" && h == srcHash {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// linkLocal puts the content at from into place at to via a hardlink,
+// degrading to a local copy on filesystems without them, and stamps
+// the source's mtime so the next sync's up-to-date check passes.
+func linkLocal(from, to string, info os.FileInfo) error {
+	os.Remove(to)
+	if err := os.Link(from, to); err != nil {
+		return CopyFile(from, to, info)
+	}
+	return os.Chtimes(to, info.ModTime(), info.ModTime())
+}
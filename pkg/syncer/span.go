@@ -0,0 +1,26 @@
+package syncer
+
+// The binary traces the walk and copy phases; the engine must not
+// depend on its tracer, so spans go through this hook instead. It is
+// nil by default and every call site tolerates that.
+
+// Span is the minimal tracing surface the engine emits to.
+type Span interface {
+	SetAttr(key, value string)
+	End()
+}
+
+// StartSpan, when set, is called at the start of each pipeline phase.
+var StartSpan func(name string) Span
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttr(key, value string) {}
+func (noopSpan) End()                      {}
+
+func startSpan(name string) Span {
+	if StartSpan == nil {
+		return noopSpan{}
+	}
+	return StartSpan(name)
+}
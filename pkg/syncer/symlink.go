@@ -0,0 +1,51 @@
+package syncer
+
+import (
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// Symlink policy: payloads occasionally ship symlinks (versioned .so
+// names, convenience aliases) and what dest should hold is a choice.
+// "preserve" recreates the link, "materialize" dereferences it into a
+// real copy, "forbid" rejects it. The default preserves on Unix and
+// materializes on Windows, where creating symlinks usually needs a
+// privilege ordinary users lack.
+const (
+	SymlinkMaterialize = "materialize"
+	SymlinkPreserve    = "preserve"
+	SymlinkForbid      = "forbid"
+)
+
+// symlinkPolicy resolves the configured policy, applying the per-OS
+// default when unset.
+func symlinkPolicy(cfg *config.Config) (string, error) {
+	switch cfg.DestSymlinks {
+	case SymlinkMaterialize, SymlinkPreserve, SymlinkForbid:
+		return cfg.DestSymlinks, nil
+	case "":
+		if runtime.GOOS == "windows" {
+			return SymlinkMaterialize, nil
+		}
+		return SymlinkPreserve, nil
+	}
+	return "", fmt.Errorf("dest_symlinks: unknown policy %q", cfg.DestSymlinks)
+}
+
+// preserveSymlink recreates the source symlink at dp, leaving an
+// already-correct link alone. Only the real filesystem has symlinks;
+// callers fall back to materializing elsewhere.
+func preserveSymlink(sp, dp string) error {
+	target, err := os.Readlink(sp)
+	if err != nil {
+		return err
+	}
+	if existing, err := os.Readlink(dp); err == nil && existing == target {
+		return nil
+	}
+	os.Remove(dp)
+	return os.Symlink(target, dp)
+}
@@ -0,0 +1,632 @@
+// Package syncer is the file-synchronization core of the wrapper: it
+// copies or mirrors payload trees into a destination, with overlay
+// merging, protected paths, parallel walking and copying, resumable
+// chunked copies for huge files and platform-native copy fast paths.
+// It is importable on its own so other installers can embed it.
+package syncer
+
+import (
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Stats is filled in by SyncDir and reported after the run.
+type Stats struct {
+	Copied  int64
+	Linked  int64
+	Skipped int64
+	Deleted int64
+	Failed  int64
+	Bytes   int64
+}
+
+// SyncDir copies the source tree into dest. Files whose size and
+// mtime already match are skipped unless cfg.Force is set. In mirror
+// mode, files and directories present in dest but not in the source
+// are removed afterwards.
+func SyncDir(ctx context.Context, src, dest string, cfg *config.Config, stats *Stats) error {
+	seen := make(map[string]bool)
+	if err := syncTree(ctx, src, dest, cfg, stats, seen); err != nil {
+		return err
+	}
+	if cfg.Mode == "mirror" {
+		if err := MirrorDelete(dest, seen, cfg, stats); err != nil {
+			return fmt.Errorf("mirror delete: %w", err)
+		}
+	} else if hasPolicy(cfg.Paths, PolicyMirror) {
+		if err := mirrorScoped(dest, seen, cfg, stats); err != nil {
+			return fmt.Errorf("mirror delete: %w", err)
+		}
+	}
+	return nil
+}
+
+// SyncLayers merges several source trees into dest in priority order:
+// the base payload first, then each overlay, so later layers win.
+// Mirror deletion considers a file wanted if any layer provides it.
+func SyncLayers(ctx context.Context, layers []string, dest string, cfg *config.Config, stats *Stats) error {
+	seen := make(map[string]bool)
+	for _, layer := range layers {
+		if err := syncTree(ctx, layer, dest, cfg, stats, seen); err != nil {
+			return fmt.Errorf("layer %s: %w", layer, err)
+		}
+	}
+	if cfg.Mode == "mirror" {
+		if err := MirrorDelete(dest, seen, cfg, stats); err != nil {
+			return fmt.Errorf("mirror delete: %w", err)
+		}
+	} else if hasPolicy(cfg.Paths, PolicyMirror) {
+		if err := mirrorScoped(dest, seen, cfg, stats); err != nil {
+			return fmt.Errorf("mirror delete: %w", err)
+		}
+	}
+	return nil
+}
+
+// syncTree copies one source tree into dest, marking every path it
+// provides in seen.
+func syncTree(ctx context.Context, src, dest string, cfg *config.Config, stats *Stats, seen map[string]bool) error {
+	if err := validatePathPolicies(cfg.Paths); err != nil {
+		return err
+	}
+	srcInfo, err := fsys.Stat(src)
+	if err != nil {
+		return fmt.Errorf("source not accessible: %w", err)
+	}
+	if err := fsys.MkdirAll(dest, 0o755); err != nil {
+		return fmt.Errorf("create destination: %w", err)
+	}
+	if !srcInfo.IsDir() {
+		// Single-file payload (e.g. a downloaded source): sync just
+		// that file into the destination root.
+		rel := filepath.Base(src)
+		seen[filepath.ToSlash(rel)] = true
+		dp := filepath.Join(dest, rel)
+		if !cfg.Force && upToDate(dp, srcInfo) {
+			stats.Skipped++
+			return nil
+		}
+		if _, err := fsys.Stat(dp); err == nil && Protected(rel, cfg.Protected) {
+			stats.Skipped++
+			return nil
+		}
+		if sameContent(src, dp, srcInfo) {
+			stats.Skipped++
+			return nil
+		}
+		if err := CopyFile(src, dp, srcInfo); err != nil {
+			return fmt.Errorf("copy %s: %w", rel, err)
+		}
+		stats.Copied++
+		stats.Bytes += srcInfo.Size()
+		return nil
+	}
+
+	type job struct {
+		rel  string
+		info os.FileInfo
+	}
+	man, err := loadManifest(src, cfg)
+	if err != nil {
+		return err
+	}
+
+	var jobs []job
+	emitPhase("walk")
+	walkSpan := startSpan("sync.walk")
+	err = walkTree(ctx, src, func(rel string, info os.FileInfo) error {
+		pol := pathPolicy(rel, cfg.Paths)
+		if Excluded(rel, cfg.Excludes) || pol == PolicySkip {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if man != nil {
+			if info.IsDir() {
+				if !man.wantsDir(rel) {
+					return filepath.SkipDir
+				}
+			} else if !man.wantsFile(rel) {
+				return nil
+			}
+		}
+		seen[filepath.ToSlash(rel)] = true
+		if info.IsDir() {
+			if _, onDisk := fsys.(osFS); onDisk && (isLinkDir(rel, cfg.LinkDirs) || pol == PolicyLink) {
+				if err := linkDirInto(src, dest, rel); err != nil {
+					return err
+				}
+				stats.Linked++
+				return filepath.SkipDir
+			}
+			return fsys.MkdirAll(filepath.Join(dest, rel), 0o755)
+		}
+		jobs = append(jobs, job{rel: rel, info: info})
+		return nil
+	})
+	walkSpan.SetAttr("files", fmt.Sprintf("%d", len(jobs)))
+	walkSpan.End()
+	if err != nil {
+		return fmt.Errorf("walk source: %w", err)
+	}
+
+	policy, err := symlinkPolicy(cfg)
+	if err != nil {
+		return err
+	}
+	renames := newRenameIndex(dest, cfg)
+
+	// A bounded errgroup instead of one goroutine per file, and every
+	// failure is collected rather than only the first: one bad file
+	// should not hide the other nine, and the user can fix the whole
+	// batch in one pass.
+	emitPhase("copy")
+	copySpan := startSpan("sync.copy")
+	var failMu sync.Mutex
+	var failures []error
+	var lockHits int64
+	avWindow := avRetryWindow(cfg)
+	g := new(errgroup.Group)
+	g.SetLimit(copyWorkers())
+	for _, j := range jobs {
+		j := j
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			sp := filepath.Join(src, j.rel)
+			dp := filepath.Join(dest, j.rel)
+			fail := func(failure error) {
+				atomic.AddInt64(&stats.Failed, 1)
+				emitError(failure)
+				failMu.Lock()
+				failures = append(failures, failure)
+				failMu.Unlock()
+			}
+			info := j.info
+			if info.Mode()&os.ModeSymlink != 0 {
+				_, onDisk := fsys.(osFS)
+				switch {
+				case policy == SymlinkForbid:
+					fail(fmt.Errorf("symlink %s rejected by dest_symlinks=forbid", j.rel))
+					return nil
+				case policy == SymlinkPreserve && onDisk:
+					if err := preserveSymlink(sp, dp); err != nil {
+						fail(fmt.Errorf("symlink %s: %w", j.rel, err))
+					} else {
+						atomic.AddInt64(&stats.Copied, 1)
+					}
+					return nil
+				default:
+					// Materialize: sync the link target's content
+					// through the normal checks below.
+					ti, err := fsys.Stat(sp)
+					if err != nil || ti.IsDir() {
+						fail(fmt.Errorf("materialize symlink %s: %v", j.rel, err))
+						return nil
+					}
+					info = ti
+				}
+			}
+			if !cfg.Force && upToDate(dp, info) {
+				debugf(1, "skip %s: size and mtime match", j.rel)
+				atomic.AddInt64(&stats.Skipped, 1)
+				return nil
+			}
+			if _, err := fsys.Stat(dp); err == nil &&
+				(Protected(j.rel, cfg.Protected) || pathPolicy(j.rel, cfg.Paths) == PolicyProtect) {
+				debugf(1, "skip %s: protected", j.rel)
+				atomic.AddInt64(&stats.Skipped, 1)
+				return nil
+			}
+			if sameContent(sp, dp, info) {
+				debugf(1, "skip %s: content identical", j.rel)
+				atomic.AddInt64(&stats.Skipped, 1)
+				return nil
+			}
+			if renames != nil {
+				if from, ok := renames.lookup(sp, j.rel, info); ok {
+					if err := linkLocal(filepath.Join(dest, filepath.FromSlash(from)), dp, info); err == nil {
+						log.Printf("rename: %s reused from %s", j.rel, from)
+						atomic.AddInt64(&stats.Linked, 1)
+						return nil
+					}
+				}
+			}
+			emitFileStart(j.rel, info.Size())
+			err := copyWithRetry(sp, dp, info, avWindow, &lockHits)
+			emitFileDone(j.rel, info.Size(), err)
+			if err != nil {
+				fail(fmt.Errorf("copy %s: %w", j.rel, err))
+				return nil
+			}
+			atomic.AddInt64(&stats.Copied, 1)
+			atomic.AddInt64(&stats.Bytes, info.Size())
+			return nil
+		})
+	}
+	waitErr := g.Wait()
+	copySpan.SetAttr("copied", fmt.Sprintf("%d", stats.Copied))
+	copySpan.SetAttr("bytes", fmt.Sprintf("%d", stats.Bytes))
+	copySpan.SetAttr("failed", fmt.Sprintf("%d", stats.Failed))
+	copySpan.End()
+	reportAVInterference(dest, atomic.LoadInt64(&lockHits))
+	if waitErr != nil {
+		// Cancellation: the remaining jobs were abandoned, not failed.
+		return waitErr
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d files failed: %w", len(failures), len(jobs), errors.Join(failures...))
+	}
+	return nil
+}
+
+// sameContent reports whether dest already holds byte-identical
+// content for the source file. It only bothers hashing when the sizes
+// match, so genuinely changed files usually fail the cheap check
+// first. Skipping identical files even under -force avoids gratuitous
+// rewrites that set off incremental backup tools and AV scanners.
+func sameContent(sp, dp string, srcInfo os.FileInfo) bool {
+	di, err := fsys.Stat(dp)
+	if err != nil || di.Size() != srcInfo.Size() {
+		return false
+	}
+	sh, err := FileSHA256(sp)
+	if err != nil {
+		return false
+	}
+	dh, err := FileSHA256(dp)
+	if err != nil {
+		return false
+	}
+	debugf(2, "hash compare %s: src %.12s… dest %.12s…", dp, sh, dh)
+	return sh == dh
+}
+
+// workerLimit, when positive, further caps copyWorkers; the wrapper
+// sets it when a power policy throttles battery operation.
+var workerLimit atomic.Int32
+
+// SetWorkerLimit caps concurrent copies at n; zero removes the cap.
+func SetWorkerLimit(n int) { workerLimit.Store(int32(n)) }
+
+// copyWorkers bounds concurrent file copies (and, via walkWorkers,
+// directory scans): one per core, capped so a big machine does not
+// thrash the destination disk.
+func copyWorkers() int {
+	n := runtime.NumCPU()
+	if n > 8 {
+		n = 8
+	}
+	if l := int(workerLimit.Load()); l > 0 && n > l {
+		n = l
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// upToDate reports whether the destination file already matches the
+// source's size and modification time.
+func upToDate(dp string, srcInfo os.FileInfo) bool {
+	di, err := fsys.Stat(dp)
+	if err != nil {
+		return false
+	}
+	return di.Size() == srcInfo.Size() && !di.ModTime().Before(srcInfo.ModTime())
+}
+
+// CopyFile copies a single regular file, preserving mode and mtime.
+// When the first attempt fails against a destination file that lost
+// its write bit since the last sync (a read-only attribute set by the
+// app, or tighter permissions from a machine-scope install), the bit
+// is cleared for the overwrite and the old mode restored on the new
+// file, instead of failing the whole sync.
+func CopyFile(src, dest string, info os.FileInfo) error {
+	err := copyFileOnce(src, dest, info)
+	if err == nil {
+		return nil
+	}
+	restore, cleared := clearReadOnly(dest)
+	if !cleared {
+		return err
+	}
+	if err := copyFileOnce(src, dest, info); err != nil {
+		restore()
+		return err
+	}
+	restore()
+	return nil
+}
+
+// clearReadOnly makes a non-writable destination file writable and
+// returns a func that re-applies the original mode (to whatever file
+// then sits at the path); ok is false when there is nothing to clear.
+func clearReadOnly(dest string) (restore func(), ok bool) {
+	di, err := fsys.Stat(dest)
+	if err != nil || di.Mode().Perm()&0o200 != 0 {
+		return nil, false
+	}
+	if fsys.Chmod(dest, di.Mode().Perm()|0o200) != nil {
+		return nil, false
+	}
+	return func() { fsys.Chmod(dest, di.Mode().Perm()) }, true
+}
+
+// copyFileOnce is one copy attempt. The data goes to a temp file in
+// the destination directory first and is renamed into place only once
+// fully written, so an interrupted or failed copy never leaves a
+// truncated file where the up-to-date check or the entry program
+// could mistake it for the real thing.
+func copyFileOnce(src, dest string, info os.FileInfo) (err error) {
+	if info.Size() >= bigFileThreshold {
+		return copyFileChunked(src, dest, info)
+	}
+	// The platform copy (CopyFileExW, copy_file_range, clonefile) is
+	// both faster and richer than a read/write loop; the portable path
+	// below is the fallback when it declines the file. It only applies
+	// to the real filesystem.
+	if _, onDisk := fsys.(osFS); onDisk {
+		if handled, err := nativeCopyFile(src, dest, info); handled {
+			return err
+		}
+	}
+	in, err := fsys.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := fsys.CreateTemp(filepath.Dir(dest), ".wrapper-tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := out.Name()
+	defer func() {
+		if err != nil {
+			out.Close()
+			fsys.Remove(tmp)
+		}
+	}()
+	if _, err = io.Copy(out, in); err != nil {
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	if err = fsys.Chmod(tmp, info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err = fsys.Chtimes(tmp, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	return fsys.Rename(tmp, dest)
+}
+
+// MirrorDelete removes everything under dest that the source walk did
+// not see. Deeper paths are removed first so directories empty out
+// before they are deleted themselves.
+func MirrorDelete(dest string, seen map[string]bool, cfg *config.Config, stats *Stats) error {
+	return mirrorPass(dest, seen, cfg, stats, func(string) bool { return true })
+}
+
+// mirrorScoped deletes strays only under paths whose policy is
+// "mirror", for configs whose global mode is copy.
+func mirrorScoped(dest string, seen map[string]bool, cfg *config.Config, stats *Stats) error {
+	return mirrorPass(dest, seen, cfg, stats, func(rel string) bool {
+		return pathPolicy(rel, cfg.Paths) == PolicyMirror
+	})
+}
+
+func mirrorPass(dest string, seen map[string]bool, cfg *config.Config, stats *Stats, want func(rel string) bool) error {
+	var extra []string
+	err := walkTree(context.Background(), dest, func(rel string, info os.FileInfo) error {
+		if strings.HasPrefix(rel, MetaPrefix) {
+			return nil
+		}
+		if rel == DownloadsDirName || strings.HasPrefix(rel, StagingDirPrefix) {
+			return filepath.SkipDir
+		}
+		pol := pathPolicy(rel, cfg.Paths)
+		if Protected(rel, cfg.Protected) || pol == PolicyProtect || pol == PolicySkip {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !seen[filepath.ToSlash(rel)] && want(rel) {
+			extra = append(extra, filepath.Join(dest, rel))
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("walk destination: %w", err)
+	}
+	emitPhase("mirror")
+	sort.Sort(sort.Reverse(sort.StringSlice(extra)))
+	for _, path := range extra {
+		log.Printf("mirror: removing %s", path)
+		if err := fsys.RemoveAll(path); err != nil {
+			return fmt.Errorf("remove %s: %w", path, err)
+		}
+		atomic.AddInt64(&stats.Deleted, 1)
+	}
+	return nil
+}
+
+// excluded reports whether rel matches one of the configured exclude
+// prefixes.
+func Excluded(rel string, excludes []string) bool {
+	slashRel := filepath.ToSlash(rel)
+	for _, ex := range excludes {
+		ex = strings.Trim(filepath.ToSlash(ex), "/")
+		if ex == "" {
+			continue
+		}
+		if slashRel == ex || strings.HasPrefix(slashRel, ex+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// CombineDigests folds a layer digest into the running version
+// digest, so any overlay change produces a new version.
+func CombineDigests(a, b string) string {
+	h := sha256.New()
+	io.WriteString(h, a)
+	io.WriteString(h, b)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// RemovePayload deletes dest at uninstall. With protected paths
+// configured the tree is removed file by file so user data survives;
+// directories that still hold protected content stay behind.
+func RemovePayload(dest string, cfg *config.Config) error {
+	if len(cfg.Protected) == 0 {
+		return fsys.RemoveAll(dest)
+	}
+	var paths []string
+	err := walkTree(context.Background(), dest, func(rel string, info os.FileInfo) error {
+		if Protected(rel, cfg.Protected) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		paths = append(paths, filepath.Join(dest, rel))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// Children sort after their parents, so deleting in reverse order
+	// empties directories before their os.Remove; ones still holding
+	// protected files simply fail and stay.
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+	for _, p := range paths {
+		fsys.Remove(p)
+	}
+	fsys.Remove(dest)
+	return nil
+}
+
+// protected reports whether the dest-relative rel is covered by a
+// protected pattern: a "saves/"-style prefix, a plain path, or a glob
+// matched against both the whole relative path and the base name
+// ("*.ini").
+func Protected(rel string, patterns []string) bool {
+	slashRel := filepath.ToSlash(rel)
+	for _, p := range patterns {
+		p = strings.Trim(filepath.ToSlash(p), "/")
+		if p == "" {
+			continue
+		}
+		if strings.ContainsAny(p, "*?[") {
+			if ok, _ := path.Match(p, slashRel); ok {
+				return true
+			}
+			if ok, _ := path.Match(p, path.Base(slashRel)); ok {
+				return true
+			}
+			continue
+		}
+		if slashRel == p || strings.HasPrefix(slashRel, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+const VersionFileName = ".wrapper-version"
+
+// DownloadsDirName and StagingDirPrefix are dest subtrees owned by
+// the wrapper's download and prefetch stages; the mirror pass must
+// never treat them as payload strays.
+const (
+	DownloadsDirName = ".downloads"
+	StagingDirPrefix = ".staging-"
+)
+
+// MetaPrefix marks the wrapper's own top-level bookkeeping
+// files in dest (version stamp, change manifest, control socket);
+// mirror mode must never treat them as payload strays.
+const MetaPrefix = ".wrapper-"
+
+// SourceDigest hashes the relative paths, sizes and mtimes of every
+// file in the source tree. It is cheap (no file contents) and changes
+// whenever 0install swaps in a new implementation.
+func SourceDigest(src string, excludes []string) (string, error) {
+	h := sha256.New()
+	if info, err := fsys.Stat(src); err != nil {
+		return "", err
+	} else if !info.IsDir() {
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", filepath.Base(src), info.Size(), info.ModTime().UnixNano())
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+	type entry struct {
+		rel   string
+		size  int64
+		mtime int64
+	}
+	var files []entry
+	err := walkTree(context.Background(), src, func(rel string, info os.FileInfo) error {
+		if Excluded(rel, excludes) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		files = append(files, entry{filepath.ToSlash(rel), info.Size(), info.ModTime().UnixNano()})
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	// The parallel walk has no fixed visit order; sort so the digest
+	// stays deterministic.
+	sort.Slice(files, func(a, b int) bool { return files[a].rel < files[b].rel })
+	for _, f := range files {
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", f.rel, f.size, f.mtime)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ReadVersion returns the digest recorded by the last successful
+// sync, or "" if dest was never synced.
+func ReadVersion(dest string) string {
+	data, err := fsys.ReadFile(filepath.Join(dest, VersionFileName))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// WriteVersion records the synced digest in dest.
+func WriteVersion(dest, digest string) error {
+	return fsys.WriteFile(filepath.Join(dest, VersionFileName), []byte(digest+"\n"), 0o644)
+}
@@ -0,0 +1,691 @@
+package syncer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+)
+
+// memFS is an in-memory FS for exercising the engine without disk.
+// Individual paths can be rigged to fail on open, standing in for
+// locked or corrupt files.
+type memFS struct {
+	mu       sync.Mutex
+	dirs     map[string]bool
+	files    map[string]*memFile
+	failOpen map[string]error
+	tmpSeq   int
+}
+
+type memFile struct {
+	data  []byte
+	mode  os.FileMode
+	mtime time.Time
+}
+
+func newMemFS() *memFS {
+	return &memFS{
+		dirs:     map[string]bool{".": true},
+		files:    map[string]*memFile{},
+		failOpen: map[string]error{},
+	}
+}
+
+func (m *memFS) put(path, content string, mtime time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = filepath.Clean(path)
+	m.files[path] = &memFile{data: []byte(content), mode: 0o644, mtime: mtime}
+	for d := filepath.Dir(path); d != "." && d != string(filepath.Separator); d = filepath.Dir(d) {
+		m.dirs[d] = true
+	}
+}
+
+func (m *memFS) Open(name string) (File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	if err := m.failOpen[name]; err != nil {
+		return nil, err
+	}
+	f, ok := m.files[name]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	return &memHandle{fs: m, path: name, data: append([]byte(nil), f.data...)}, nil
+}
+
+func (m *memFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	if flag&os.O_WRONLY == 0 && flag&os.O_RDWR == 0 {
+		return m.Open(name)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	f, ok := m.files[name]
+	if !ok || flag&os.O_TRUNC != 0 {
+		f = &memFile{mode: perm, mtime: time.Now()}
+		m.files[name] = f
+	}
+	h := &memHandle{fs: m, path: name, write: true, data: append([]byte(nil), f.data...)}
+	if flag&os.O_APPEND != 0 {
+		h.pos = int64(len(h.data))
+	}
+	return h, nil
+}
+
+func (m *memFS) CreateTemp(dir, pattern string) (File, error) {
+	m.mu.Lock()
+	m.tmpSeq++
+	name := filepath.Join(dir, strings.Replace(pattern, "*", fmt.Sprint(m.tmpSeq), 1))
+	m.mu.Unlock()
+	return m.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+}
+
+func (m *memFS) Stat(name string) (os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	if f, ok := m.files[name]; ok {
+		return memInfo{name: filepath.Base(name), size: int64(len(f.data)), mode: f.mode, mtime: f.mtime}, nil
+	}
+	if m.dirs[name] {
+		return memInfo{name: filepath.Base(name), mode: os.ModeDir | 0o755, dir: true}, nil
+	}
+	return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+}
+
+func (m *memFS) ReadDir(name string) ([]os.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	if !m.dirs[name] {
+		return nil, &os.PathError{Op: "readdir", Path: name, Err: os.ErrNotExist}
+	}
+	seen := map[string]os.DirEntry{}
+	add := func(path string, info memInfo) {
+		rel, err := filepath.Rel(name, path)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return
+		}
+		first := strings.Split(rel, string(filepath.Separator))[0]
+		if first != rel {
+			seen[first] = memEntry{memInfo{name: first, mode: os.ModeDir | 0o755, dir: true}}
+			return
+		}
+		info.name = first
+		seen[first] = memEntry{info}
+	}
+	for p, f := range m.files {
+		add(p, memInfo{size: int64(len(f.data)), mode: f.mode, mtime: f.mtime})
+	}
+	for d := range m.dirs {
+		add(d, memInfo{mode: os.ModeDir | 0o755, dir: true})
+	}
+	var out []os.DirEntry
+	for _, e := range seen {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Name() < out[b].Name() })
+	return out, nil
+}
+
+func (m *memFS) ReadFile(name string) ([]byte, error) {
+	f, err := m.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+func (m *memFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	m.put(name, string(data), time.Now())
+	return nil
+}
+
+func (m *memFS) MkdirAll(path string, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = filepath.Clean(path)
+	for d := path; d != "." && d != string(filepath.Separator); d = filepath.Dir(d) {
+		m.dirs[d] = true
+	}
+	return nil
+}
+
+func (m *memFS) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	f, ok := m.files[oldpath]
+	if !ok {
+		return &os.PathError{Op: "rename", Path: oldpath, Err: os.ErrNotExist}
+	}
+	m.files[newpath] = f
+	delete(m.files, oldpath)
+	return nil
+}
+
+func (m *memFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name = filepath.Clean(name)
+	delete(m.files, name)
+	delete(m.dirs, name)
+	return nil
+}
+
+func (m *memFS) RemoveAll(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	path = filepath.Clean(path)
+	for p := range m.files {
+		if p == path || strings.HasPrefix(p, path+string(filepath.Separator)) {
+			delete(m.files, p)
+		}
+	}
+	for d := range m.dirs {
+		if d == path || strings.HasPrefix(d, path+string(filepath.Separator)) {
+			delete(m.dirs, d)
+		}
+	}
+	return nil
+}
+
+func (m *memFS) Chmod(name string, mode os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if f, ok := m.files[filepath.Clean(name)]; ok {
+		f.mode = mode
+	}
+	return nil
+}
+
+func (m *memFS) Chtimes(name string, atime, mtime time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if f, ok := m.files[filepath.Clean(name)]; ok {
+		f.mtime = mtime
+	}
+	return nil
+}
+
+type memHandle struct {
+	fs    *memFS
+	path  string
+	data  []byte
+	pos   int64
+	write bool
+}
+
+func (h *memHandle) Read(p []byte) (int, error) {
+	if h.pos >= int64(len(h.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, h.data[h.pos:])
+	h.pos += int64(n)
+	return n, nil
+}
+
+func (h *memHandle) Write(p []byte) (int, error) {
+	for int64(len(h.data)) < h.pos {
+		h.data = append(h.data, 0)
+	}
+	h.data = append(h.data[:h.pos], p...)
+	h.pos += int64(len(p))
+	return len(p), nil
+}
+
+func (h *memHandle) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		h.pos = offset
+	case io.SeekCurrent:
+		h.pos += offset
+	case io.SeekEnd:
+		h.pos = int64(len(h.data)) + offset
+	}
+	return h.pos, nil
+}
+
+func (h *memHandle) Truncate(size int64) error {
+	if size < int64(len(h.data)) {
+		h.data = h.data[:size]
+	}
+	return nil
+}
+
+func (h *memHandle) Sync() error  { return nil }
+func (h *memHandle) Name() string { return h.path }
+
+func (h *memHandle) Close() error {
+	if !h.write {
+		return nil
+	}
+	h.fs.mu.Lock()
+	defer h.fs.mu.Unlock()
+	f, ok := h.fs.files[h.path]
+	if !ok {
+		f = &memFile{mode: 0o644, mtime: time.Now()}
+		h.fs.files[h.path] = f
+	}
+	f.data = append([]byte(nil), h.data...)
+	return nil
+}
+
+type memInfo struct {
+	name  string
+	size  int64
+	mode  os.FileMode
+	mtime time.Time
+	dir   bool
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) Mode() os.FileMode  { return i.mode }
+func (i memInfo) ModTime() time.Time { return i.mtime }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() any           { return nil }
+
+type memEntry struct{ info memInfo }
+
+func (e memEntry) Name() string               { return e.info.name }
+func (e memEntry) IsDir() bool                { return e.info.dir }
+func (e memEntry) Type() os.FileMode          { return e.info.mode.Type() }
+func (e memEntry) Info() (fs.FileInfo, error) { return e.info, nil }
+
+func TestSyncDirCopiesAndMirrors(t *testing.T) {
+	m := newMemFS()
+	defer SetFS(SetFS(m))
+	mtime := time.Now().Add(-time.Hour)
+	m.put("/src/a.txt", "alpha", mtime)
+	m.put("/src/sub/b.txt", "beta", mtime)
+	m.put("/dest/stray.txt", "old", mtime)
+	m.put("/dest/saves/game.dat", "progress", mtime)
+
+	cfg := &config.Config{Mode: "mirror", Protected: []string{"saves/"}}
+	var stats Stats
+	if err := SyncDir(context.Background(), "/src", "/dest", cfg, &stats); err != nil {
+		t.Fatalf("SyncDir: %v", err)
+	}
+	if got, _ := m.ReadFile("/dest/sub/b.txt"); string(got) != "beta" {
+		t.Errorf("dest/sub/b.txt = %q, want %q", got, "beta")
+	}
+	if _, err := m.Stat("/dest/stray.txt"); err == nil {
+		t.Errorf("mirror mode left stray.txt behind")
+	}
+	if got, _ := m.ReadFile("/dest/saves/game.dat"); string(got) != "progress" {
+		t.Errorf("protected file was touched: %q", got)
+	}
+	if stats.Copied != 2 {
+		t.Errorf("Copied = %d, want 2", stats.Copied)
+	}
+}
+
+func TestSyncTreeReportsEveryFailure(t *testing.T) {
+	m := newMemFS()
+	defer SetFS(SetFS(m))
+	mtime := time.Now().Add(-time.Hour)
+	m.put("/src/ok.txt", "fine", mtime)
+	m.put("/src/locked.txt", "nope", mtime)
+	m.failOpen["/src/locked.txt"] = &os.PathError{Op: "open", Path: "/src/locked.txt", Err: os.ErrPermission}
+
+	var stats Stats
+	err := SyncDir(context.Background(), "/src", "/dest", &config.Config{Mode: "copy"}, &stats)
+	if err == nil {
+		t.Fatalf("SyncDir succeeded despite locked file")
+	}
+	if !strings.Contains(err.Error(), "1 of 2 files failed") {
+		t.Errorf("error = %q, want a 1-of-2 summary", err)
+	}
+	if got, _ := m.ReadFile("/dest/ok.txt"); string(got) != "fine" {
+		t.Errorf("healthy file was not copied alongside the failure")
+	}
+	if stats.Failed != 1 {
+		t.Errorf("Failed = %d, want 1", stats.Failed)
+	}
+}
+
+func TestVersionRoundTrip(t *testing.T) {
+	m := newMemFS()
+	defer SetFS(SetFS(m))
+	if v := ReadVersion("/dest"); v != "" {
+		t.Errorf("ReadVersion on fresh dest = %q, want empty", v)
+	}
+	if err := WriteVersion("/dest", "abc123"); err != nil {
+		t.Fatalf("WriteVersion: %v", err)
+	}
+	if v := ReadVersion("/dest"); v != "abc123" {
+		t.Errorf("ReadVersion = %q, want abc123", v)
+	}
+}
+
+type countingEvents struct {
+	mu   sync.Mutex
+	done int
+}
+
+func (c *countingEvents) OnPhaseChange(string)      {}
+func (c *countingEvents) OnFileStart(string, int64) {}
+func (c *countingEvents) OnError(error)             {}
+func (c *countingEvents) OnFileDone(rel string, size int64, err error) {
+	c.mu.Lock()
+	c.done++
+	c.mu.Unlock()
+}
+
+func TestSyncerOptions(t *testing.T) {
+	m := newMemFS()
+	mtime := time.Now().Add(-time.Hour)
+	m.put("/src/a.txt", "alpha", mtime)
+	m.put("/src/b.txt", "beta", mtime)
+
+	ev := &countingEvents{}
+	s := New(WithFS(m), WithEvents(ev))
+	stats, err := s.Sync(context.Background(), "/src", "/dest", &config.Config{Mode: "copy"})
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if stats.Copied != 2 {
+		t.Errorf("Copied = %d, want 2", stats.Copied)
+	}
+	if ev.done != 2 {
+		t.Errorf("OnFileDone fired %d times, want 2", ev.done)
+	}
+	if got, _ := m.ReadFile("/dest/a.txt"); string(got) != "alpha" {
+		t.Errorf("dest/a.txt = %q, want alpha", got)
+	}
+}
+
+func TestDetectRenames(t *testing.T) {
+	src, dest := t.TempDir(), t.TempDir()
+	big := bytes.Repeat([]byte("payload."), renameMinSize/8+1)
+	if err := os.WriteFile(filepath.Join(src, "old.bin"), big, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{Mode: "mirror", DetectRenames: true}
+	var stats Stats
+	if err := SyncDir(context.Background(), src, dest, cfg, &stats); err != nil {
+		t.Fatalf("initial SyncDir: %v", err)
+	}
+	if err := os.Rename(filepath.Join(src, "old.bin"), filepath.Join(src, "new.bin")); err != nil {
+		t.Fatal(err)
+	}
+	stats = Stats{}
+	if err := SyncDir(context.Background(), src, dest, cfg, &stats); err != nil {
+		t.Fatalf("second SyncDir: %v", err)
+	}
+	if stats.Linked != 1 || stats.Copied != 0 {
+		t.Errorf("Linked = %d, Copied = %d, want 1 linked and 0 copied", stats.Linked, stats.Copied)
+	}
+	got, err := os.ReadFile(filepath.Join(dest, "new.bin"))
+	if err != nil || !bytes.Equal(got, big) {
+		t.Errorf("dest/new.bin wrong after rename sync (err %v)", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "old.bin")); err == nil {
+		t.Errorf("mirror pass left old.bin behind")
+	}
+}
+
+func TestDedupDest(t *testing.T) {
+	dest := t.TempDir()
+	blob := bytes.Repeat([]byte("runtime."), dedupMinSize/8+1)
+	for _, p := range []string{"plugins/a/lib.dll", "plugins/b/lib.dll", "saves/lib.dll"} {
+		full := filepath.Join(dest, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, blob, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cfg := &config.Config{Protected: []string{"saves/"}}
+	links, saved, err := DedupDest(dest, cfg)
+	if err != nil {
+		t.Fatalf("DedupDest: %v", err)
+	}
+	if links != 1 || saved != int64(len(blob)) {
+		t.Errorf("links = %d, saved = %d, want 1 and %d", links, saved, len(blob))
+	}
+	ai, _ := os.Stat(filepath.Join(dest, "plugins/a/lib.dll"))
+	bi, _ := os.Stat(filepath.Join(dest, "plugins/b/lib.dll"))
+	si, _ := os.Stat(filepath.Join(dest, "saves/lib.dll"))
+	if !os.SameFile(ai, bi) {
+		t.Errorf("duplicates not linked")
+	}
+	if os.SameFile(ai, si) {
+		t.Errorf("protected file was linked")
+	}
+}
+
+func TestCopyFileOverwritesReadOnly(t *testing.T) {
+	m := newMemFS()
+	defer SetFS(SetFS(m))
+	mtime := time.Now().Add(-time.Hour)
+	m.put("/src/a.cfg", "new", time.Now())
+	m.put("/dest/a.cfg", "old", mtime)
+	m.Chmod("/dest/a.cfg", 0o444)
+
+	restore, ok := clearReadOnly("/dest/a.cfg")
+	if !ok {
+		t.Fatal("clearReadOnly declined a read-only file")
+	}
+	if di, _ := m.Stat("/dest/a.cfg"); di.Mode().Perm()&0o200 == 0 {
+		t.Fatal("write bit not set")
+	}
+	restore()
+	if di, _ := m.Stat("/dest/a.cfg"); di.Mode().Perm()&0o200 != 0 {
+		t.Fatal("original mode not restored")
+	}
+	if _, ok := clearReadOnly("/dest/missing"); ok {
+		t.Error("clearReadOnly claimed a missing file")
+	}
+}
+
+func TestSymlinkPolicies(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlink creation needs privilege on Windows")
+	}
+	newTree := func() (string, string) {
+		src, dest := t.TempDir(), t.TempDir()
+		if err := os.WriteFile(filepath.Join(src, "lib.so.1"), []byte("code"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink("lib.so.1", filepath.Join(src, "lib.so")); err != nil {
+			t.Fatal(err)
+		}
+		return src, dest
+	}
+
+	src, dest := newTree()
+	var stats Stats
+	cfg := &config.Config{Mode: "copy", DestSymlinks: SymlinkPreserve}
+	if err := SyncDir(context.Background(), src, dest, cfg, &stats); err != nil {
+		t.Fatalf("preserve: %v", err)
+	}
+	if target, err := os.Readlink(filepath.Join(dest, "lib.so")); err != nil || target != "lib.so.1" {
+		t.Errorf("preserve: link = %q, %v", target, err)
+	}
+
+	src, dest = newTree()
+	stats = Stats{}
+	cfg.DestSymlinks = SymlinkMaterialize
+	if err := SyncDir(context.Background(), src, dest, cfg, &stats); err != nil {
+		t.Fatalf("materialize: %v", err)
+	}
+	if fi, err := os.Lstat(filepath.Join(dest, "lib.so")); err != nil || fi.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("materialize left a symlink (err %v)", err)
+	}
+
+	src, dest = newTree()
+	stats = Stats{}
+	cfg.DestSymlinks = SymlinkForbid
+	if err := SyncDir(context.Background(), src, dest, cfg, &stats); err == nil {
+		t.Error("forbid accepted a symlink")
+	}
+
+	cfg.DestSymlinks = "bogus"
+	if err := SyncDir(context.Background(), src, dest, cfg, &stats); err == nil {
+		t.Error("unknown policy accepted")
+	}
+}
+
+func TestLinkDirs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("junction creation not exercised in CI")
+	}
+	src, dest := t.TempDir(), t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "assets"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "assets", "big.dat"), []byte("static"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "app.bin"), []byte("code"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{Mode: "mirror", LinkDirs: []string{"assets"}}
+	var stats Stats
+	if err := SyncDir(context.Background(), src, dest, cfg, &stats); err != nil {
+		t.Fatalf("SyncDir: %v", err)
+	}
+	if fi, err := os.Lstat(filepath.Join(dest, "assets")); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("dest/assets is not a link (err %v)", err)
+	}
+	if got, err := os.ReadFile(filepath.Join(dest, "assets", "big.dat")); err != nil || string(got) != "static" {
+		t.Errorf("linked content unreadable: %q, %v", got, err)
+	}
+	if stats.Linked != 1 {
+		t.Errorf("Linked = %d, want 1", stats.Linked)
+	}
+	// Second sync: the correct link is left alone, not recreated.
+	stats = Stats{}
+	if err := SyncDir(context.Background(), src, dest, cfg, &stats); err != nil {
+		t.Fatalf("resync: %v", err)
+	}
+	if fi, err := os.Lstat(filepath.Join(dest, "assets")); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("resync broke the link (err %v)", err)
+	}
+}
+
+func TestPathPolicies(t *testing.T) {
+	m := newMemFS()
+	defer SetFS(SetFS(m))
+	mtime := time.Now().Add(-time.Hour)
+	m.put("/src/app.bin", "code", mtime)
+	m.put("/src/docs/readme.txt", "docs", mtime)
+	m.put("/src/conf/user.ini", "defaults", mtime)
+	m.put("/dest/conf/user.ini", "tuned", mtime.Add(-time.Hour))
+	m.put("/dest/plugins/stale.dll", "old", mtime)
+	m.put("/src/plugins/fresh.dll", "new", mtime)
+
+	cfg := &config.Config{Mode: "copy", Paths: map[string]string{
+		"docs":    PolicySkip,
+		"conf":    PolicyProtect,
+		"plugins": PolicyMirror,
+	}}
+	var stats Stats
+	if err := SyncDir(context.Background(), "/src", "/dest", cfg, &stats); err != nil {
+		t.Fatalf("SyncDir: %v", err)
+	}
+	if _, err := m.Stat("/dest/docs/readme.txt"); err == nil {
+		t.Error("skip policy copied docs anyway")
+	}
+	if got, _ := m.ReadFile("/dest/conf/user.ini"); string(got) != "tuned" {
+		t.Errorf("protect policy overwrote user.ini: %q", got)
+	}
+	if _, err := m.Stat("/dest/plugins/stale.dll"); err == nil {
+		t.Error("mirror policy left stale.dll")
+	}
+	if got, _ := m.ReadFile("/dest/plugins/fresh.dll"); string(got) != "new" {
+		t.Errorf("plugins/fresh.dll = %q", got)
+	}
+	if got, _ := m.ReadFile("/dest/app.bin"); string(got) != "code" {
+		t.Errorf("app.bin = %q", got)
+	}
+
+	cfg.Paths["docs"] = "bogus"
+	if err := SyncDir(context.Background(), "/src", "/dest", cfg, &stats); err == nil {
+		t.Error("unknown policy accepted")
+	}
+}
+
+func TestManifestPartialInstall(t *testing.T) {
+	m := newMemFS()
+	defer SetFS(SetFS(m))
+	mtime := time.Now().Add(-time.Hour)
+	m.put("/src/app.bin", "code", mtime)
+	m.put("/src/lib/core.dll", "lib", mtime)
+	m.put("/src/docs/manual.pdf", "docs", mtime)
+	m.put("/src/install.manifest", "# runtime set\nlib\n", mtime)
+
+	cfg := &config.Config{Mode: "copy", Entry: "app.bin", Manifest: "install.manifest"}
+	var stats Stats
+	if err := SyncDir(context.Background(), "/src", "/dest", cfg, &stats); err != nil {
+		t.Fatalf("SyncDir: %v", err)
+	}
+	if got, _ := m.ReadFile("/dest/lib/core.dll"); string(got) != "lib" {
+		t.Errorf("manifest path not synced: %q", got)
+	}
+	if got, _ := m.ReadFile("/dest/app.bin"); string(got) != "code" {
+		t.Errorf("entry not synced: %q", got)
+	}
+	if _, err := m.Stat("/dest/docs/manual.pdf"); err == nil {
+		t.Error("unlisted docs were synced")
+	}
+
+	cfg.Full = true
+	if err := SyncDir(context.Background(), "/src", "/dest", cfg, &stats); err != nil {
+		t.Fatalf("SyncDir -full: %v", err)
+	}
+	if _, err := m.Stat("/dest/docs/manual.pdf"); err != nil {
+		t.Error("-full did not sync the complete payload")
+	}
+
+	cfg.Full = false
+	cfg.Manifest = "missing.manifest"
+	if err := SyncDir(context.Background(), "/src", "/dest", cfg, &stats); err == nil {
+		t.Error("missing manifest accepted")
+	}
+}
+
+func TestCopyWithRetryPassesThroughErrors(t *testing.T) {
+	m := newMemFS()
+	defer SetFS(SetFS(m))
+	m.put("/src/a.txt", "hello", time.Now())
+	info, _ := m.Stat("/src/a.txt")
+
+	var locks int64
+	if err := copyWithRetry("/src/a.txt", "/dest/a.txt", info, time.Second, &locks); err != nil {
+		t.Fatalf("copyWithRetry: %v", err)
+	}
+	if got, _ := m.ReadFile("/dest/a.txt"); string(got) != "hello" {
+		t.Errorf("copied content = %q", got)
+	}
+	// A plain not-found error is not a scanner lock and must come back
+	// immediately, without spinning out the retry window.
+	start := time.Now()
+	if err := copyWithRetry("/src/missing", "/dest/missing", info, time.Hour, &locks); err == nil {
+		t.Error("missing source accepted")
+	}
+	if time.Since(start) > time.Second {
+		t.Error("non-transient error was retried")
+	}
+	if locks != 0 {
+		t.Errorf("locks = %d, want 0", locks)
+	}
+}
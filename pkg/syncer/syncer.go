@@ -0,0 +1,62 @@
+package syncer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/doudou0720/Aiwb_0Installer_Feed/pkg/config"
+)
+
+// Syncer is the stable embedding surface over the engine: a
+// constructor, functional options, and one Sync call. Downstream
+// tools should depend on this rather than the package-level
+// SetFS/SetEvents plumbing, which exists for the wrapper command and
+// for tests. The exported API of pkg/... follows semantic
+// versioning: within a major version options may be added but never
+// removed or repurposed.
+type Syncer struct {
+	events Events
+	fs     FS
+}
+
+// Option configures a Syncer.
+type Option func(*Syncer)
+
+// WithEvents installs a progress sink for the Syncer's runs.
+func WithEvents(e Events) Option {
+	return func(s *Syncer) { s.events = e }
+}
+
+// WithFS runs the Syncer against an alternative filesystem.
+func WithFS(f FS) Option {
+	return func(s *Syncer) { s.fs = f }
+}
+
+// New returns a Syncer with the given options applied.
+func New(opts ...Option) *Syncer {
+	s := &Syncer{}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// apiMu serializes Sync calls: the engine's hooks are package state,
+// so concurrent Syncers with different options must take turns.
+var apiMu sync.Mutex
+
+// Sync runs one copy/mirror pass from src into dest and returns the
+// resulting stats.
+func (s *Syncer) Sync(ctx context.Context, src, dest string, cfg *config.Config) (Stats, error) {
+	apiMu.Lock()
+	defer apiMu.Unlock()
+	if s.fs != nil {
+		defer SetFS(SetFS(s.fs))
+	}
+	if s.events != nil {
+		defer SetEvents(SetEvents(s.events))
+	}
+	var stats Stats
+	err := SyncDir(ctx, src, dest, cfg, &stats)
+	return stats, err
+}
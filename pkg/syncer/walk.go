@@ -0,0 +1,77 @@
+package syncer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// The source walk used to be a single-threaded filepath.Walk. On trees
+// with hundreds of thousands of entries the enumeration itself is a
+// significant share of sync time — especially on network filesystems,
+// where every stat is a round trip — so walkTree scans sibling
+// directories concurrently instead.
+
+// walkWorkers bounds concurrent directory scans, matching the copy
+// pool.
+func walkWorkers() int {
+	return copyWorkers()
+}
+
+// walkTree calls fn for every entry under root (root itself excluded)
+// with a path relative to root. Directories are scanned concurrently
+// but fn itself is serialized, so callers need no locking; a directory
+// is always reported before its contents. Returning filepath.SkipDir
+// from fn prunes that directory. Visit order is not deterministic —
+// callers that need one must sort what they collect.
+func walkTree(ctx context.Context, root string, fn func(rel string, info os.FileInfo) error) error {
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(walkWorkers())
+	var mu sync.Mutex
+	var scan func(rel string) error
+	scan = func(rel string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		entries, err := fsys.ReadDir(filepath.Join(root, rel))
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			sub := filepath.Join(rel, e.Name())
+			info, err := e.Info()
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			err = fn(sub, info)
+			mu.Unlock()
+			if err == filepath.SkipDir {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if e.IsDir() {
+				sub := sub
+				// TryGo rather than Go: when every worker slot is busy
+				// the scan continues inline, so workers never block
+				// waiting for slots only they can free.
+				if !g.TryGo(func() error { return scan(sub) }) {
+					if err := scan(sub); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+	err := scan("")
+	if werr := g.Wait(); err == nil {
+		err = werr
+	}
+	return err
+}
@@ -0,0 +1,8 @@
+// Package version carries the wrapper's own version string, split out
+// so both the binary and programs embedding the sync engine can report
+// which engine they were built against.
+package version
+
+// Version is stamped by release builds via
+// -ldflags "-X github.com/doudou0720/Aiwb_0Installer_Feed/pkg/version.Version=...".
+var Version = "dev"